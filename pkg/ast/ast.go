@@ -1,11 +1,19 @@
 // Package ast TODO: package docs
 package ast
 
-// These are the available root node types. In JSON it will either be an
-// object or an array at the base.
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/bradford-hamilton/dora/pkg/token"
+)
+
+// These are the available root node types. In JSON it will usually be an
+// object or an array at the base, but a document can also be a single literal.
 const (
 	ObjectRoot RootNodeType = iota
 	ArrayRoot
+	LiteralRoot
 )
 
 // RootNodeType is a type alias for an int
@@ -45,25 +53,92 @@ type StructuralItem struct {
 	Value string
 }
 
+// Position describes a location in the source document. Lines and columns start at 1,
+// and columns are counted in bytes. The zero Position is not a valid location.
+type Position struct {
+	Offset int // byte offset, starting at 0
+	Line   int // line number, starting at 1
+	Column int // column number, starting at 1
+}
+
+// IsValid reports whether the position points at a location in a source document.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns the position as "line:column", or "-" when it isn't valid.
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Span is the source range covered by a node. Start is the first byte of the node
+// and End is the position directly after its last byte.
+type Span struct {
+	Start Position
+	End   Position
+}
+
+// String returns the span as "line:column-line:column".
+func (s Span) String() string {
+	return s.Start.String() + "-" + s.End.String()
+}
+
+// SpanOf returns the source range covered by any node in the tree. Value and ArrayItem
+// wrappers report the range of the content they hold. Nodes that weren't created by the
+// parser have an invalid Span. Object and Array keep their positions in a Span field rather
+// than in Start and End, so this is the one accessor that works for every node.
+func SpanOf(node ValueContent) Span {
+	switch n := node.(type) {
+	case Object:
+		return n.Span
+	case Array:
+		return n.Span
+	case ArrayItem:
+		return Span{Start: n.Start, End: n.End}
+	case Literal:
+		return Span{Start: n.Start, End: n.End}
+	case Property:
+		return Span{Start: n.Start, End: n.End}
+	case Identifier:
+		return Span{Start: n.Start, End: n.End}
+	case Value:
+		return Span{Start: n.Start, End: n.End}
+	case *Value:
+		if n != nil {
+			return Span{Start: n.Start, End: n.End}
+		}
+	}
+	return Span{}
+}
+
 // Object represents a JSON object. It holds a slice of Property as its children,
-// a Type ("Object"), and start & end code points for displaying.
+// a Type ("Object"), and start & end positions of its braces. Unlike other nodes, Start
+// and End are plain byte offsets, kept for existing users, with the full positions in
+// Span. Use SpanOf to get the range of any node the same way.
 type Object struct {
 	Type            Type
 	Children        []Property
-	Start           int
-	End             int
+	Start           int  // The byte offset of the opening brace
+	End             int  // The byte offset just past the closing brace
+	Span            Span // Start and End with their lines and columns
 	SuffixStructure []StructuralItem
 }
 
 // Array represents a JSON array It holds a slice of Value as its children,
-// a Type ("Array"), and start & end code points for displaying.
+// a Type ("Array"), and start & end positions of its brackets. Like Object, Start and
+// End are plain byte offsets and Span holds the full positions. Use SpanOf to get the
+// range of any node the same way.
 type Array struct {
 	Type            Type
 	PrefixStructure []StructuralItem
 	Children        []ArrayItem
 	SuffixStructure []StructuralItem
-	Start           int
-	End             int
+	Start           int  // The byte offset of the opening bracket
+	End             int  // The byte offset just past the closing bracket
+	Span            Span // Start and End with their lines and columns
 }
 
 // Array holds a Type ("ArrayItem") as well as a `Value` and whether there is a comma after the item
@@ -73,6 +148,8 @@ type ArrayItem struct {
 	Value              ValueContent
 	PostValueStructure []StructuralItem
 	HasCommaSeparator  bool
+	Start              Position
+	End                Position
 }
 
 // Literal represents a JSON literal value. It holds a Type ("Literal") and the actual value.
@@ -82,6 +159,38 @@ type Literal struct {
 	Value             ValueContent
 	Delimiter         string // Delimiter is set for string values
 	OriginalRendering string // Allows preservig numeric formatting from source documents
+	Start             Position
+	End               Position
+}
+
+// Decoded returns a string literal's value with its escape sequences decoded. The Value
+// field holds the string as it was written in the source.
+func (l Literal) Decoded() (string, error) {
+	s, ok := l.Value.(string)
+	if !ok || l.ValueType != StringLiteralValueType {
+		return "", fmt.Errorf("literal is not a string")
+	}
+	return token.Unescape(s)
+}
+
+// NumberText returns the text of a number literal as it was written in the source, or
+// formats its value when the literal wasn't parsed from a document.
+func (l Literal) NumberText() string {
+	if l.OriginalRendering != "" {
+		return l.OriginalRendering
+	}
+	switch n := l.Value.(type) {
+	case int64:
+		return strconv.FormatInt(n, 10)
+	case int:
+		return strconv.Itoa(n)
+	case float64:
+		return strconv.FormatFloat(n, 'g', -1, 64)
+	case string:
+		return n
+	default:
+		return ""
+	}
 }
 
 // Property holds a Type ("Property") as well as a `Key` and `Value`. The Key is an Identifier
@@ -95,6 +204,8 @@ type Property struct {
 	Value              ValueContent
 	PostValueStructure []StructuralItem
 	HasCommaSeparator  bool
+	Start              Position // Start of the key
+	End                Position // End of the value
 }
 
 // Identifier represents a JSON object property key
@@ -102,12 +213,53 @@ type Identifier struct {
 	Type      Type
 	Value     string // "key1"
 	Delimiter string
+	Start     Position
+	End       Position
+}
+
+// Decoded returns the key with its escape sequences decoded.
+func (i Identifier) Decoded() (string, error) {
+	return token.Unescape(i.Value)
 }
 
+// Value wraps any JSON value with the whitespace and comments around it. Start and End
+// cover the content only.
 type Value struct {
 	PrefixStructure []StructuralItem
 	Content         ValueContent
 	SuffixStructure []StructuralItem
+	Start           Position
+	End             Position
+}
+
+// Unwrap strips the RootNode, Value, and ArrayItem wrappers around a node, leaving the
+// Object, Array, or Literal inside.
+func Unwrap(node ValueContent) ValueContent {
+	for {
+		switch n := node.(type) {
+		case RootNode:
+			if n.RootValue == nil {
+				return nil
+			}
+			node = n.RootValue.Content
+		case *RootNode:
+			if n == nil || n.RootValue == nil {
+				return nil
+			}
+			node = n.RootValue.Content
+		case Value:
+			node = n.Content
+		case *Value:
+			if n == nil {
+				return nil
+			}
+			node = n.Content
+		case ArrayItem:
+			node = n.Value
+		default:
+			return node
+		}
+	}
 }
 
 // ValueContent will eventually have some methods that all Values must implement. For now
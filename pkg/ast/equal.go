@@ -0,0 +1,321 @@
+package ast
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// EqualOption configures how Equal and Hash compare nodes.
+type EqualOption func(*equalOptions)
+
+type equalOptions struct {
+	keyOrder   bool
+	arrayOrder bool
+}
+
+// KeyOrder sets whether the order of keys in an object matters. By default it doesn't,
+// so `{"a": 1, "b": 2}` equals `{"b": 2, "a": 1}`.
+func KeyOrder(matters bool) EqualOption {
+	return func(o *equalOptions) {
+		o.keyOrder = matters
+	}
+}
+
+// ArrayOrder sets whether the order of items in an array matters. By default it does.
+// When it doesn't, arrays are compared as multisets, so `[1, 2, 2]` equals `[2, 1, 2]`
+// but not `[1, 1, 2]`.
+func ArrayOrder(matters bool) EqualOption {
+	return func(o *equalOptions) {
+		o.arrayOrder = matters
+	}
+}
+
+func newEqualOptions(opts []EqualOption) equalOptions {
+	o := equalOptions{arrayOrder: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Equal reports whether two nodes hold semantically equal JSON values. Formatting, comments,
+// quoting style, string escapes, and number spelling are ignored, so `1.0` equals `1` and
+// `"A"` equals `'A'`. Key order is ignored and array order matters unless changed with
+// KeyOrder and ArrayOrder. Nodes may be a RootNode, Value, ArrayItem, Object, Array, or Literal.
+func Equal(a, b ValueContent, opts ...EqualOption) bool {
+	o := newEqualOptions(opts)
+	return equal(Unwrap(a), Unwrap(b), &o)
+}
+
+// Hash returns a semantic hash of a node. Nodes that are Equal with the same options have the
+// same hash, which makes it useful for finding duplicate subtrees or as a cache key. The hash is
+// stable across processes and versions of Go.
+func Hash(node ValueContent, opts ...EqualOption) uint64 {
+	o := newEqualOptions(opts)
+	return hash(Unwrap(node), &o)
+}
+
+func equal(a, b ValueContent, o *equalOptions) bool {
+	switch x := a.(type) {
+	case Object:
+		y, ok := b.(Object)
+		return ok && equalObjects(x, y, o)
+	case Array:
+		y, ok := b.(Array)
+		return ok && equalArrays(x, y, o)
+	case Literal:
+		y, ok := b.(Literal)
+		return ok && equalLiterals(x, y)
+	default:
+		return false
+	}
+}
+
+func equalObjects(a, b Object, o *equalOptions) bool {
+	if o.keyOrder {
+		if len(a.Children) != len(b.Children) {
+			return false
+		}
+		for i := range a.Children {
+			if decodedKey(a.Children[i].Key) != decodedKey(b.Children[i].Key) ||
+				!equal(Unwrap(a.Children[i].Value), Unwrap(b.Children[i].Value), o) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Without key order, objects behave like maps. Like queries, the first duplicate key wins.
+	aMembers, bMembers := members(a), members(b)
+	if len(aMembers) != len(bMembers) {
+		return false
+	}
+	for key, aValue := range aMembers {
+		bValue, ok := bMembers[key]
+		if !ok || !equal(aValue, bValue, o) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalArrays(a, b Array, o *equalOptions) bool {
+	if len(a.Children) != len(b.Children) {
+		return false
+	}
+	if o.arrayOrder {
+		for i := range a.Children {
+			if !equal(Unwrap(a.Children[i].Value), Unwrap(b.Children[i].Value), o) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Without array order, match every item in a with an unused equal item in b. Hashes
+	// narrow down the candidates so we only deep compare items that are likely equal.
+	candidates := make(map[uint64][]int, len(b.Children))
+	for i, item := range b.Children {
+		h := hash(Unwrap(item.Value), o)
+		candidates[h] = append(candidates[h], i)
+	}
+	for _, item := range a.Children {
+		aValue := Unwrap(item.Value)
+		h := hash(aValue, o)
+		matched := -1
+		for n, i := range candidates[h] {
+			if equal(aValue, Unwrap(b.Children[i].Value), o) {
+				matched = n
+				break
+			}
+		}
+		if matched < 0 {
+			return false
+		}
+		candidates[h] = slices.Delete(candidates[h], matched, matched+1)
+	}
+	return true
+}
+
+func equalLiterals(a, b Literal) bool {
+	if a.ValueType != b.ValueType {
+		return false
+	}
+	switch a.ValueType {
+	case StringLiteralValueType:
+		return decodedString(a) == decodedString(b)
+	case NumberLiteralValueType:
+		return canonicalNumber(a.NumberText()) == canonicalNumber(b.NumberText())
+	case BooleanLiteralValueType:
+		return a.Value == b.Value
+	default:
+		return true // null
+	}
+}
+
+// The type tags written before each value when hashing, so that different kinds of values
+// with similar contents don't collide.
+const (
+	hashObject byte = iota + 1
+	hashArray
+	hashString
+	hashNumber
+	hashTrue
+	hashFalse
+	hashNull
+)
+
+func hash(node ValueContent, o *equalOptions) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	writeHash := func(v uint64) {
+		binary.BigEndian.PutUint64(buf[:], v)
+		h.Write(buf[:])
+	}
+
+	switch n := node.(type) {
+	case Object:
+		h.Write([]byte{hashObject})
+		var memberHashes []uint64
+		if o.keyOrder {
+			for _, prop := range n.Children {
+				memberHashes = append(memberHashes, hashMember(decodedKey(prop.Key), Unwrap(prop.Value), o))
+			}
+		} else {
+			for key, value := range members(n) {
+				memberHashes = append(memberHashes, hashMember(key, value, o))
+			}
+			slices.Sort(memberHashes)
+		}
+		for _, mh := range memberHashes {
+			writeHash(mh)
+		}
+	case Array:
+		h.Write([]byte{hashArray})
+		itemHashes := make([]uint64, 0, len(n.Children))
+		for _, item := range n.Children {
+			itemHashes = append(itemHashes, hash(Unwrap(item.Value), o))
+		}
+		if !o.arrayOrder {
+			slices.Sort(itemHashes)
+		}
+		for _, ih := range itemHashes {
+			writeHash(ih)
+		}
+	case Literal:
+		switch n.ValueType {
+		case StringLiteralValueType:
+			h.Write([]byte{hashString})
+			h.Write([]byte(decodedString(n)))
+		case NumberLiteralValueType:
+			h.Write([]byte{hashNumber})
+			h.Write([]byte(canonicalNumber(n.NumberText())))
+		case BooleanLiteralValueType:
+			if n.Value == true {
+				h.Write([]byte{hashTrue})
+			} else {
+				h.Write([]byte{hashFalse})
+			}
+		default:
+			h.Write([]byte{hashNull})
+		}
+	}
+
+	return h.Sum64()
+}
+
+func hashMember(key string, value ValueContent, o *equalOptions) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(len(key)))
+	h.Write(buf[:])
+	h.Write([]byte(key))
+	binary.BigEndian.PutUint64(buf[:], hash(value, o))
+	h.Write(buf[:])
+	return h.Sum64()
+}
+
+// members returns the object's members keyed by their decoded key. The first duplicate key wins,
+// matching the value a query for the key selects.
+func members(obj Object) map[string]ValueContent {
+	m := make(map[string]ValueContent, len(obj.Children))
+	for _, prop := range obj.Children {
+		key := decodedKey(prop.Key)
+		if _, ok := m[key]; !ok {
+			m[key] = Unwrap(prop.Value)
+		}
+	}
+	return m
+}
+
+// decodedKey returns the key with its escape sequences decoded. Keys with invalid escapes are
+// compared as they were written.
+func decodedKey(key Identifier) string {
+	if s, err := key.Decoded(); err == nil {
+		return s
+	}
+	return key.Value
+}
+
+// decodedString returns a string literal's value with its escape sequences decoded.
+func decodedString(lit Literal) string {
+	if s, err := lit.Decoded(); err == nil {
+		return s
+	}
+	s, _ := lit.Value.(string)
+	return s
+}
+
+// canonicalNumber rewrites a JSON number into a single spelling for its exact decimal value, so
+// numbers can be compared without rounding through float64. The result is an optional `-`, the
+// significant digits without leading or trailing zeros, `e`, and the exponent. Zero is always "0".
+// Text that isn't a valid number is returned unchanged.
+func canonicalNumber(num string) string {
+	s := num
+	var negative bool
+	if strings.HasPrefix(s, "-") {
+		negative = true
+		s = s[1:]
+	}
+
+	mantissa, exponent := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(strings.TrimPrefix(s[i+1:], "+"))
+		if err != nil {
+			return num
+		}
+		mantissa, exponent = s[:i], exp
+	}
+
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	if intPart == "" && fracPart == "" {
+		return num
+	}
+	for _, part := range [...]string{intPart, fracPart} {
+		for i := 0; i < len(part); i++ {
+			if part[i] < '0' || part[i] > '9' {
+				return num
+			}
+		}
+	}
+
+	digits := strings.TrimLeft(intPart+fracPart, "0")
+	exponent -= len(fracPart)
+	if digits == "" {
+		return "0"
+	}
+	trimmed := strings.TrimRight(digits, "0")
+	exponent += len(digits) - len(trimmed)
+
+	var b strings.Builder
+	if negative {
+		b.WriteByte('-')
+	}
+	b.WriteString(trimmed)
+	b.WriteByte('e')
+	b.WriteString(strconv.Itoa(exponent))
+	return b.String()
+}
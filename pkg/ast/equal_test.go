@@ -0,0 +1,79 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/bradford-hamilton/dora/pkg/ast"
+	"github.com/bradford-hamilton/dora/pkg/lexer"
+	"github.com/bradford-hamilton/dora/pkg/parser"
+)
+
+func TestEqual(t *testing.T) {
+	tests := [...]struct {
+		a        string
+		b        string
+		opts     []ast.EqualOption
+		expected bool
+	}{
+		{a: `{"a": 1, "b": [true, null]}`, b: "{\n\t\"a\":1,\n\t\"b\":[ true, null ] // comment\n}", expected: true},
+		{a: `{"a": 1, "b": 2}`, b: `{"b": 2, "a": 1}`, expected: true},
+		{a: `{"a": 1, "b": 2}`, b: `{"b": 2, "a": 1}`, opts: []ast.EqualOption{ast.KeyOrder(true)}, expected: false},
+		{a: `{"a": 1, "b": 2}`, b: `{"a": 1, "b": 2}`, opts: []ast.EqualOption{ast.KeyOrder(true)}, expected: true},
+		{a: `{"a": 1}`, b: `{"a": 1, "b": 2}`, expected: false},
+		{a: `[1.0, 100, -0, 0.5]`, b: `[1, 1e2, 0, 5E-1]`, expected: true},
+		{a: `[1.5]`, b: `[1.50000000000000000001]`, expected: false},
+		{a: `["A\n", 'it\'s']`, b: `["A\u000a", "it's"]`, expected: true},
+		{a: `["😀"]`, b: `["😀"]`, expected: true},
+		{a: `[1, 2, 2]`, b: `[2, 1, 2]`, expected: false},
+		{a: `[1, 2, 2]`, b: `[2, 1, 2]`, opts: []ast.EqualOption{ast.ArrayOrder(false)}, expected: true},
+		{a: `[1, 2, 2]`, b: `[1, 1, 2]`, opts: []ast.EqualOption{ast.ArrayOrder(false)}, expected: false},
+		{a: `[{"a": [1, 2]}, 3]`, b: `[3, {"a": [2, 1]}]`, opts: []ast.EqualOption{ast.ArrayOrder(false)}, expected: true},
+		{a: `[true]`, b: `["true"]`, expected: false},
+		{a: `[null]`, b: `[false]`, expected: false},
+		{a: `[1]`, b: `{"1": 1}`, expected: false},
+		{a: `{"a": 1, "a": 2}`, b: `{"a": 1}`, expected: true},
+		{a: `{"a": 1, "a": 2}`, b: `{"a": 2}`, expected: false},
+	}
+
+	for _, tt := range tests {
+		a, b := parse(t, tt.a), parse(t, tt.b)
+
+		if equal := ast.Equal(a, b, tt.opts...); equal != tt.expected {
+			t.Fatalf("Expected Equal(%s, %s) to be %t, got: %t", tt.a, tt.b, tt.expected, equal)
+		}
+		if tt.expected && ast.Hash(a, tt.opts...) != ast.Hash(b, tt.opts...) {
+			t.Fatalf("Expected equal values %s and %s to have the same hash", tt.a, tt.b)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	const stableHash uint64 = 0xd620096711372263
+
+	a := parse(t, `{"id": 1, "tags": ["x", "y"]}`)
+	b := parse(t, `{"id": 2, "tags": ["x", "y"]}`)
+
+	if ast.Hash(a) == ast.Hash(b) {
+		t.Fatalf("Expected different values to have different hashes")
+	}
+
+	// The hash is stable, so it's safe to persist as a cache key
+	if h := ast.Hash(a); h != stableHash {
+		t.Fatalf("Expected hash of %#x, got: %#x", stableHash, h)
+	}
+	if ast.Hash(a) != ast.Hash(parse(t, `{ "tags": ["x", "y"], "id": 1.0 }`)) {
+		t.Fatalf("Expected the hash to ignore key order and number spelling")
+	}
+	if ast.Hash(a, ast.KeyOrder(true)) == ast.Hash(parse(t, `{"tags": ["x", "y"], "id": 1}`), ast.KeyOrder(true)) {
+		t.Fatalf("Expected the hash to depend on key order when it matters")
+	}
+}
+
+func parse(t *testing.T, input string) ast.RootNode {
+	t.Helper()
+	tree, err := parser.New(lexer.New(input)).ParseJSON()
+	if err != nil {
+		t.Fatalf("Failed to parse %s. Error: %v", input, err)
+	}
+	return tree
+}
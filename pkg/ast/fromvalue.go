@@ -0,0 +1,202 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// defaultIndent is the indentation FromValue gives each level of nesting.
+const defaultIndent = "  "
+
+// FromValue builds a tree from a Go value, so documents generated in code can be edited and
+// written out like parsed ones. The value is encoded the way encoding/json encodes it, which
+// means `json` struct tags, omitempty, and Marshaler implementations are all respected, struct
+// fields keep their declared order, and map keys are sorted. Values that encode to a string,
+// number, boolean, or null, ex: 42, build a tree whose root is a single literal.
+//
+// The nodes are given default structure: every member and item on its own line, indented two
+// spaces per level, with a space after each colon. Empty objects and arrays are written as {}
+// and []. Positions are left unset since the nodes don't come from a source document.
+func FromValue(v any) (*RootNode, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(&buf)
+	dec.UseNumber()
+	b := builder{dec: dec}
+
+	content, err := b.value(0)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &RootNode{RootValue: &Value{Content: content}}
+	switch content.(type) {
+	case Object:
+		root.Type = ObjectRoot
+	case Array:
+		root.Type = ArrayRoot
+	default:
+		root.Type = LiteralRoot
+	}
+
+	return root, nil
+}
+
+// builder turns the token stream of encoded JSON into nodes.
+type builder struct {
+	dec *json.Decoder
+}
+
+func (b *builder) value(depth int) (ValueContent, error) {
+	tok, err := b.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			return b.object(depth)
+		case '[':
+			return b.array(depth)
+		}
+		return nil, fmt.Errorf("unexpected delimiter %v", t)
+	case string:
+		return Literal{
+			Type:      LiteralType,
+			ValueType: StringLiteralValueType,
+			Value:     escapeString(t),
+			Delimiter: `"`,
+		}, nil
+	case json.Number:
+		return numberLiteral(t)
+	case bool:
+		return Literal{Type: LiteralType, ValueType: BooleanLiteralValueType, Value: t}, nil
+	case nil:
+		return Literal{Type: LiteralType, ValueType: NullLiteralValueType, Value: "null"}, nil
+	default:
+		return nil, fmt.Errorf("unexpected token %v", tok)
+	}
+}
+
+func (b *builder) object(depth int) (ValueContent, error) {
+	obj := Object{Type: ObjectType}
+	for b.dec.More() {
+		tok, err := b.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected an object key, got: %v", tok)
+		}
+		value, err := b.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		obj.Children = append(obj.Children, Property{
+			Type:              PropertyType,
+			PrefixStructure:   lineStructure(depth + 1),
+			Key:               Identifier{Type: IdentifierType, Value: escapeString(key), Delimiter: `"`},
+			PreValueStructure: []StructuralItem{{Value: " "}},
+			Value:             value,
+		})
+	}
+	if err := b.closing(); err != nil {
+		return nil, err
+	}
+
+	if len(obj.Children) > 0 {
+		for i := range obj.Children[:len(obj.Children)-1] {
+			obj.Children[i].HasCommaSeparator = true
+		}
+		obj.SuffixStructure = lineStructure(depth)
+	}
+
+	return obj, nil
+}
+
+func (b *builder) array(depth int) (ValueContent, error) {
+	arr := Array{Type: ArrayType}
+	for b.dec.More() {
+		value, err := b.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		arr.Children = append(arr.Children, ArrayItem{
+			Type:            ArrayItemType,
+			PrefixStructure: lineStructure(depth + 1),
+			Value:           value,
+		})
+	}
+	if err := b.closing(); err != nil {
+		return nil, err
+	}
+
+	if len(arr.Children) > 0 {
+		for i := range arr.Children[:len(arr.Children)-1] {
+			arr.Children[i].HasCommaSeparator = true
+		}
+		arr.SuffixStructure = lineStructure(depth)
+	}
+
+	return arr, nil
+}
+
+// closing consumes the delimiter that closes an object or array.
+func (b *builder) closing() error {
+	if _, err := b.dec.Token(); err != nil {
+		if errors.Is(err, io.EOF) {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return nil
+}
+
+// lineStructure is the structure that starts a new line indented to depth.
+func lineStructure(depth int) []StructuralItem {
+	return []StructuralItem{{Value: "\n" + strings.Repeat(defaultIndent, depth)}}
+}
+
+// numberLiteral builds a number literal the way the parser does: integers hold an int64 and
+// everything else a float64, with the text kept as the literal's rendering.
+func numberLiteral(n json.Number) (Literal, error) {
+	lit := Literal{
+		Type:              LiteralType,
+		ValueType:         NumberLiteralValueType,
+		OriginalRendering: n.String(),
+	}
+	if i, err := strconv.ParseInt(n.String(), 10, 64); err == nil {
+		lit.Value = i
+		return lit, nil
+	}
+	f, err := strconv.ParseFloat(n.String(), 64)
+	if err != nil {
+		return Literal{}, err
+	}
+	lit.Value = f
+	return lit, nil
+}
+
+// escapeString returns s escaped for use between double quotes, which is how string literals
+// and keys hold their values.
+func escapeString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s) // Encoding a string can't fail
+	quoted := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	return string(quoted[1 : len(quoted)-1])
+}
@@ -0,0 +1,116 @@
+package ast_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bradford-hamilton/dora/pkg/ast"
+	"github.com/bradford-hamilton/dora/pkg/lexer"
+	"github.com/bradford-hamilton/dora/pkg/parser"
+)
+
+type testPet struct {
+	Name string `json:"dog_name"`
+}
+
+type testUser struct {
+	FirstName string    `json:"first_name"`
+	Email     string    `json:"email,omitempty"`
+	Age       int       `json:"age"`
+	Allergies *string   `json:"allergies"`
+	Pets      []testPet `json:"pets"`
+	Tags      []string  `json:"tags"`
+	Joined    time.Time `json:"joined"`
+	Ignored   string    `json:"-"`
+}
+
+func TestFromValue(t *testing.T) {
+	tests := [...]struct {
+		value    any
+		expected string
+	}{
+		{
+			value: testUser{
+				FirstName: "bradford",
+				Age:       30,
+				Pets:      []testPet{{Name: "ellie"}},
+				Tags:      []string{},
+				Joined:    time.Date(2020, 4, 19, 0, 0, 0, 0, time.UTC),
+				Ignored:   "ignored",
+			},
+			expected: `{
+  "first_name": "bradford",
+  "age": 30,
+  "allergies": null,
+  "pets": [
+    {
+      "dog_name": "ellie"
+    }
+  ],
+  "tags": [],
+  "joined": "2020-04-19T00:00:00Z"
+}`,
+		},
+		{
+			value: map[string]any{"b": []any{1.5, true, "<\"quoted\">\n"}, "a": map[string]int{}},
+			expected: `{
+  "a": {},
+  "b": [
+    1.5,
+    true,
+    "<\"quoted\">\n"
+  ]
+}`,
+		},
+		{value: []int{}, expected: `[]`},
+		{value: 42, expected: `42`},
+		{value: "a \"quoted\" string", expected: `"a \"quoted\" string"`},
+		{value: true, expected: `true`},
+		{value: nil, expected: `null`},
+	}
+
+	for _, tt := range tests {
+		root, err := ast.FromValue(tt.value)
+		if err != nil {
+			t.Fatalf("Failed to build a tree from %#v. Error: %v", tt.value, err)
+		}
+
+		out, err := ast.Sprint(root)
+		if err != nil {
+			t.Fatalf("Failed to print tree. Error: %v", err)
+		}
+		if out != tt.expected {
+			t.Fatalf("Expected output:\n%s\ngot:\n%s", tt.expected, out)
+		}
+
+		parsed, err := parser.New(lexer.New(out)).ParseJSON()
+		if err != nil {
+			t.Fatalf("Failed to parse output %s. Error: %v", out, err)
+		}
+		if !ast.Equal(root, parsed, ast.KeyOrder(true)) {
+			t.Fatalf("Expected the built tree to equal the parsed output %s", out)
+		}
+		if parsed.Type != root.Type {
+			t.Fatalf("Expected root type %v, got: %v", parsed.Type, root.Type)
+		}
+	}
+}
+
+func TestFromValueLiteralRoot(t *testing.T) {
+	root, err := ast.FromValue(42)
+	if err != nil {
+		t.Fatalf("Failed to build a tree from 42. Error: %v", err)
+	}
+	lit, ok := root.RootValue.Content.(ast.Literal)
+	if root.Type != ast.LiteralRoot || !ok || lit.NumberText() != "42" {
+		t.Fatalf("Expected a literal root holding 42, got: %+v", root)
+	}
+}
+
+func TestFromValueErrors(t *testing.T) {
+	for _, v := range []any{make(chan int), func() {}} {
+		if _, err := ast.FromValue(v); err == nil {
+			t.Fatalf("Expected an error building a tree from %#v", v)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package ast
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Fprint writes node to w as JSON, including the whitespace and comments held in its
+// structure, so a parsed document is written back out exactly as it was read. The node may
+// be a RootNode, Value, ArrayItem, Property, Object, Array, or Literal.
+func Fprint(w io.Writer, node ValueContent) error {
+	bw := bufio.NewWriter(w)
+	p := printer{w: bw}
+	if err := p.node(node); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Sprint returns node as a JSON string. See Fprint.
+func Sprint(node ValueContent) (string, error) {
+	var sb strings.Builder
+	if err := Fprint(&sb, node); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// printer writes nodes to a buffered writer. Write errors are reported by Flush, so the
+// individual writes don't check them.
+type printer struct {
+	w *bufio.Writer
+}
+
+func (p *printer) node(node ValueContent) error {
+	switch n := node.(type) {
+	case RootNode:
+		if n.RootValue == nil {
+			return fmt.Errorf("root node has no value")
+		}
+		return p.value(*n.RootValue)
+	case *RootNode:
+		if n == nil || n.RootValue == nil {
+			return fmt.Errorf("root node has no value")
+		}
+		return p.value(*n.RootValue)
+	case Value:
+		return p.value(n)
+	case *Value:
+		if n == nil {
+			return fmt.Errorf("nil value")
+		}
+		return p.value(*n)
+	case ArrayItem:
+		return p.arrayItem(n)
+	case Property:
+		return p.property(n)
+	case Object:
+		return p.object(n)
+	case Array:
+		return p.array(n)
+	case Literal:
+		return p.literal(n)
+	default:
+		return fmt.Errorf("unhandled node type %T", node)
+	}
+}
+
+func (p *printer) value(v Value) error {
+	p.structure(v.PrefixStructure)
+	if err := p.node(v.Content); err != nil {
+		return err
+	}
+	p.structure(v.SuffixStructure)
+	return nil
+}
+
+func (p *printer) structure(items []StructuralItem) {
+	for _, item := range items {
+		p.w.WriteString(item.Value)
+	}
+}
+
+func (p *printer) object(obj Object) error {
+	p.w.WriteByte('{')
+	for _, prop := range obj.Children {
+		if err := p.property(prop); err != nil {
+			return err
+		}
+	}
+	p.structure(obj.SuffixStructure)
+	p.w.WriteByte('}')
+	return nil
+}
+
+func (p *printer) property(prop Property) error {
+	p.structure(prop.PrefixStructure)
+	p.w.WriteString(prop.Key.Delimiter)
+	p.w.WriteString(prop.Key.Value)
+	p.w.WriteString(prop.Key.Delimiter)
+	p.structure(prop.PostKeyStructure)
+	p.w.WriteByte(':')
+	p.structure(prop.PreValueStructure)
+	if err := p.node(prop.Value); err != nil {
+		return err
+	}
+	p.structure(prop.PostValueStructure)
+	if prop.HasCommaSeparator {
+		p.w.WriteByte(',')
+	}
+	return nil
+}
+
+func (p *printer) array(arr Array) error {
+	p.structure(arr.PrefixStructure)
+	p.w.WriteByte('[')
+	for _, item := range arr.Children {
+		if err := p.arrayItem(item); err != nil {
+			return err
+		}
+	}
+	p.structure(arr.SuffixStructure)
+	p.w.WriteByte(']')
+	return nil
+}
+
+func (p *printer) arrayItem(item ArrayItem) error {
+	p.structure(item.PrefixStructure)
+	if err := p.node(item.Value); err != nil {
+		return err
+	}
+	p.structure(item.PostValueStructure)
+	if item.HasCommaSeparator {
+		p.w.WriteByte(',')
+	}
+	return nil
+}
+
+func (p *printer) literal(lit Literal) error {
+	if lit.OriginalRendering != "" {
+		p.w.WriteString(lit.OriginalRendering)
+		return nil
+	}
+	switch lit.ValueType {
+	case StringLiteralValueType:
+		s, ok := lit.Value.(string)
+		if !ok {
+			return fmt.Errorf("string literal holds %T", lit.Value)
+		}
+		p.w.WriteString(lit.Delimiter)
+		p.w.WriteString(s)
+		p.w.WriteString(lit.Delimiter)
+	case BooleanLiteralValueType:
+		fmt.Fprintf(p.w, "%t", lit.Value == true)
+	case NullLiteralValueType:
+		p.w.WriteString("null")
+	case NumberLiteralValueType:
+		p.w.WriteString(lit.NumberText())
+	default:
+		return fmt.Errorf("unhandled literal value type: %v", lit.ValueType)
+	}
+	return nil
+}
@@ -0,0 +1,487 @@
+package dora
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bradford-hamilton/dora/pkg/ast"
+)
+
+// MissingPathsError is returned by Bind when required paths aren't found in the document.
+// It lists every missing path at once rather than stopping at the first.
+type MissingPathsError struct {
+	Paths []string
+}
+
+func (e *MissingPathsError) Error() string {
+	return "missing required paths: " + strings.Join(e.Paths, ", ")
+}
+
+// Bind fills the fields of the struct pointed to by v from the paths in their `dora` struct tags:
+//
+//	type Config struct {
+//		Email   string        `dora:"$.data.users[0].email"`
+//		Age     int           `dora:"$.data.users[0].age"`
+//		Timeout time.Duration `dora:"$.timeout,optional"`
+//	}
+//
+// Paths are required unless the tag has the `optional` option. Strings, booleans, floats, ints,
+// uints, time.Time (RFC 3339 strings or Unix seconds), and time.Duration (strings like "1m30s" or
+// nanoseconds) are converted from the matching JSON value. Struct fields without a tag are bound
+// with the same document, while tagged struct fields whose type has `dora` tags of its own are
+// bound relative to the tagged value, where `$` is that value. Either is left alone when its type
+// is already being bound to that value, ex: `Next *Node` in a Node. Anything else is decoded like
+// GetInto. Every field is attempted, and the returned error lists every missing path, a key or
+// index the document doesn't have, in a *MissingPathsError joined with any other errors, like a
+// path that can match more than one value or a value that can't be converted.
+func Bind(c *Client, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Bind requires a non-nil pointer to a struct, got: %T", v)
+	}
+
+	b := binder{missing: &MissingPathsError{}, binding: map[bindKey]bool{}}
+	b.bindStruct(c, rv.Elem(), "")
+
+	var errs []error
+	if len(b.missing.Paths) > 0 {
+		errs = append(errs, b.missing)
+	}
+	return errors.Join(append(errs, b.errs...)...)
+}
+
+// binder collects the problems found while binding.
+type binder struct {
+	missing *MissingPathsError
+	errs    []error
+	binding map[bindKey]bool // The structs being bound, to stop fields rebinding the same value forever
+	bound   int              // The number of tagged fields bound so far
+}
+
+// bindKey is a struct type being bound to a value, identified by the value's offset in the input.
+type bindKey struct {
+	t      reflect.Type
+	offset int
+}
+
+// bindStruct binds every tagged field of v. The prefix is the path of the value c holds, used to
+// report full paths when binding relative to a nested value.
+func (b *binder) bindStruct(c *Client, v reflect.Value, prefix string) {
+	t := v.Type()
+	offset := ast.SpanOf(c.tree.RootValue.Content).Start.Offset
+	b.binding[bindKey{t, offset}] = true
+	defer delete(b.binding, bindKey{t, offset})
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("dora")
+		if !ok {
+			// Untagged fields are bound with the same document, so a type that holds itself, ex:
+			// `Next *Node`, is only bound once
+			if bindsUntagged(sf) && isBindableStruct(sf.Type) && !b.binding[bindKey{structType(sf.Type), offset}] {
+				fv := v.Field(i)
+				if fv.Kind() == reflect.Pointer && fv.IsNil() {
+					// A nil pointer is only allocated once one of its fields binds, so a struct whose
+					// paths are all missing and optional is left nil
+					elem, bound := reflect.New(sf.Type.Elem()), b.bound
+					b.bindStruct(c, elem.Elem(), prefix)
+					if b.bound > bound {
+						fv.Set(elem)
+					}
+					continue
+				}
+				if fv.Kind() == reflect.Pointer {
+					fv = fv.Elem()
+				}
+				b.bindStruct(c, fv, prefix)
+			}
+			continue
+		}
+		if tag == "-" || !sf.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+
+		query, optional := parseBindTag(tag)
+		fullPath := prefix + query
+		if prefix != "" {
+			fullPath = prefix + strings.TrimPrefix(query, "$")
+		}
+
+		q, err := Compile(query)
+		if err != nil {
+			b.errs = append(b.errs, fmt.Errorf("field %s: %w", sf.Name, err))
+			continue
+		}
+		node, err := c.resolveQuery(q)
+		if errors.Is(err, ErrKeyNotFound) || errors.Is(err, ErrIndexOutOfRange) {
+			if !optional {
+				b.missing.Paths = append(b.missing.Paths, fullPath)
+			}
+			continue
+		}
+		if err != nil {
+			b.errs = append(b.errs, fmt.Errorf("field %s: %w", sf.Name, err))
+			continue
+		}
+
+		if err := b.bindValue(c, node, fv, fullPath); err != nil {
+			b.errs = append(b.errs, fmt.Errorf("field %s: %w", sf.Name, err))
+			continue
+		}
+		b.bound++
+	}
+}
+
+// bindValue stores node in v, converting it based on v's type. A struct type that is already being
+// bound to node is left alone, ex: a Node field tagged `dora:"$"` in a Node, as it would recurse forever.
+func (b *binder) bindValue(c *Client, node ast.ValueContent, v reflect.Value, path string) error {
+	if isBindableStruct(v.Type()) && b.binding[bindKey{structType(v.Type()), ast.SpanOf(node).Start.Offset}] {
+		return nil
+	}
+
+	lit, isLiteral := node.(ast.Literal)
+	if isLiteral && lit.ValueType == ast.NullLiteralValueType {
+		if v.Kind() == reflect.Pointer {
+			v.SetZero()
+		}
+		return nil
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return b.bindValue(c, node, v.Elem(), path)
+	}
+
+	switch v.Type() {
+	case timeType:
+		t, err := toTime(node)
+		if err != nil {
+			return bindError(node, v.Type(), path, err)
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := toDuration(node)
+		if err != nil {
+			return bindError(node, v.Type(), path, err)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	if isBindableStruct(v.Type()) {
+		sub, err := c.subClient(node)
+		if err != nil {
+			return bindError(node, v.Type(), path, err)
+		}
+		b.bindStruct(sub, v, path)
+		return nil
+	}
+
+	d := decoder{input: c.input}
+	if implementsUnmarshaler(v) {
+		return d.decode(node, v, []byte(path))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(c.toString(node))
+		return nil
+	case reflect.Bool:
+		bl, err := toBool(node)
+		if err != nil {
+			return bindError(node, v.Type(), path, err)
+		}
+		v.SetBool(bl)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(scalarText(node), v.Type().Bits())
+		if err != nil {
+			return bindError(node, v.Type(), path, err)
+		}
+		v.SetFloat(f)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := toInt(scalarText(node))
+		if err != nil || v.OverflowInt(n) {
+			return bindError(node, v.Type(), path, err)
+		}
+		v.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := toUint(scalarText(node))
+		if err != nil || v.OverflowUint(n) {
+			return bindError(node, v.Type(), path, err)
+		}
+		v.SetUint(n)
+		return nil
+	}
+
+	return d.decode(node, v, []byte(path))
+}
+
+// subClient returns a client whose root is node, so queries can be resolved relative to it.
+func (c *Client) subClient(node ast.ValueContent) (*Client, error) {
+	root := ast.RootNode{RootValue: &ast.Value{Content: node}}
+	switch node.(type) {
+	case ast.Object:
+		root.Type = ast.ObjectRoot
+	case ast.Array:
+		root.Type = ast.ArrayRoot
+	default:
+		return nil, fmt.Errorf("expected an object or array, got %s", describeNode(node))
+	}
+	return &Client{tree: &root, input: c.input}, nil
+}
+
+// toString converts any node to a string the way GetString does, except numbers keep their
+// source spelling. Objects and arrays are returned as their JSON source text.
+func (c *Client) toString(node ast.ValueContent) string {
+	switch n := node.(type) {
+	case ast.Literal:
+		switch n.ValueType {
+		case ast.StringLiteralValueType:
+			if s, err := n.Decoded(); err == nil {
+				return s
+			}
+			return n.Value.(string)
+		case ast.NumberLiteralValueType:
+			return n.NumberText()
+		default:
+			return fmt.Sprintf("%v", n.Value)
+		}
+	default:
+		span := ast.SpanOf(n)
+		return string(c.input[span.Start.Offset:span.End.Offset])
+	}
+}
+
+// scalarText returns the text of a number literal, or the decoded value of a string literal so
+// that numbers held in strings convert as well.
+func scalarText(node ast.ValueContent) string {
+	lit, ok := node.(ast.Literal)
+	if !ok {
+		return ""
+	}
+	switch lit.ValueType {
+	case ast.NumberLiteralValueType:
+		return lit.NumberText()
+	case ast.StringLiteralValueType:
+		s, _ := lit.Decoded()
+		return s
+	default:
+		return ""
+	}
+}
+
+func toBool(node ast.ValueContent) (bool, error) {
+	lit, ok := node.(ast.Literal)
+	if ok && lit.ValueType == ast.BooleanLiteralValueType {
+		return lit.Value == true, nil
+	}
+	if ok && lit.ValueType == ast.StringLiteralValueType {
+		return strconv.ParseBool(scalarText(lit))
+	}
+	return false, errors.New("expected a boolean")
+}
+
+// toInt parses an integer, also accepting numbers like `1e3` or `30.0` that have no fraction.
+func toInt(text string) (int64, error) {
+	digits, ok := integerText(text)
+	if !ok {
+		return 0, fmt.Errorf("%s is not an integer", text)
+	}
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s overflows int64", text)
+	}
+	return n, nil
+}
+
+// toUint is toInt for unsigned integers, so values above math.MaxInt64 convert too.
+func toUint(text string) (uint64, error) {
+	digits, ok := integerText(text)
+	if !ok || strings.HasPrefix(digits, "-") {
+		return 0, fmt.Errorf("%s is not an unsigned integer", text)
+	}
+	n, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s overflows uint64", text)
+	}
+	return n, nil
+}
+
+// integerText rewrites a decimal number as plain integer digits with an optional sign, ex: `1.5e3`
+// is `1500`. It works on the text rather than a float64, so numbers beyond 2^53 keep every digit.
+// It reports false when the number has a fraction or isn't a decimal number at all.
+func integerText(text string) (string, bool) {
+	sign := ""
+	if len(text) > 0 && (text[0] == '-' || text[0] == '+') {
+		sign, text = text[:1], text[1:]
+	}
+	if sign == "+" {
+		sign = ""
+	}
+
+	mantissa, exponent := text, 0
+	if i := strings.IndexAny(text, "eE"); i >= 0 {
+		e, err := strconv.Atoi(text[i+1:])
+		if err != nil {
+			return "", false
+		}
+		mantissa, exponent = text[:i], e
+	}
+	whole, fraction, _ := strings.Cut(mantissa, ".")
+	if whole == "" && fraction == "" || strings.Trim(whole+fraction, "0123456789") != "" {
+		return "", false
+	}
+
+	// Move the fraction's digits into the whole number, then drop trailing zeros and leading zeros
+	digits := whole + fraction
+	exponent -= len(fraction)
+	for len(digits) > 0 && digits[len(digits)-1] == '0' {
+		digits = digits[:len(digits)-1]
+		exponent++
+	}
+	digits = strings.TrimLeft(digits, "0")
+	switch {
+	case digits == "":
+		return "0", true
+	case exponent < 0:
+		return "", false
+	case len(digits)+exponent > 20:
+		// Too many digits for any 64 bit integer. Rather than writing out every zero, return a
+		// number that's just as out of range
+		return sign + "1" + strings.Repeat("0", 20), true
+	}
+	return sign + digits + strings.Repeat("0", exponent), true
+}
+
+func toTime(node ast.ValueContent) (time.Time, error) {
+	lit, ok := node.(ast.Literal)
+	if ok && lit.ValueType == ast.StringLiteralValueType {
+		return time.Parse(time.RFC3339Nano, scalarText(lit))
+	}
+	if ok && lit.ValueType == ast.NumberLiteralValueType {
+		f, err := strconv.ParseFloat(lit.NumberText(), 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+	}
+	return time.Time{}, errors.New("expected an RFC 3339 string or Unix seconds")
+}
+
+func toDuration(node ast.ValueContent) (time.Duration, error) {
+	lit, ok := node.(ast.Literal)
+	if ok && lit.ValueType == ast.StringLiteralValueType {
+		return time.ParseDuration(scalarText(lit))
+	}
+	if ok && lit.ValueType == ast.NumberLiteralValueType {
+		n, err := toInt(lit.NumberText())
+		return time.Duration(n), err
+	}
+	return 0, errors.New("expected a duration string or nanoseconds")
+}
+
+func bindError(node ast.ValueContent, t reflect.Type, path string, err error) error {
+	typeErr := &UnmarshalTypeError{Value: describeNode(node), Type: t, Path: path}
+	if err == nil {
+		return typeErr
+	}
+	return fmt.Errorf("%w: %v", typeErr, err)
+}
+
+// parseBindTag splits a `dora` tag into its query and whether it's optional. Options follow
+// the query after a comma, ex: `$.timeout,optional`. Only known options are split off, as
+// queries may contain commas themselves.
+func parseBindTag(tag string) (query string, optional bool) {
+	for {
+		i := strings.LastIndexByte(tag, ',')
+		if i < 0 {
+			return tag, optional
+		}
+		switch strings.TrimSpace(tag[i+1:]) {
+		case "optional":
+			optional = true
+		case "required":
+			optional = false
+		default:
+			return tag, optional
+		}
+		tag = tag[:i]
+	}
+}
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+)
+
+// structType returns t, or the type t points to when it's a pointer.
+func structType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
+
+// isBindableStruct reports whether t (or what it points to) is a struct with `dora` tags,
+// either on its own fields or on the fields of structs it holds.
+func isBindableStruct(t reflect.Type) bool {
+	return hasBindTags(t, map[reflect.Type]bool{})
+}
+
+func hasBindTags(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType || visited[t] {
+		return false
+	}
+	visited[t] = true
+	// Only fields bindStruct would bind count, so the two agree on which structs are bindable
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if tag, ok := f.Tag.Lookup("dora"); ok {
+			if tag != "-" && f.IsExported() {
+				return true
+			}
+			continue
+		}
+		if bindsUntagged(f) && hasBindTags(f.Type, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// bindsUntagged reports whether an untagged field is followed when binding. Embedded structs are
+// followed even when their type is unexported, like encoding/json.
+func bindsUntagged(f reflect.StructField) bool {
+	return f.IsExported() || f.Anonymous && f.Type.Kind() == reflect.Struct
+}
+
+// implementsUnmarshaler reports whether v handles its own decoding, in which case it's left to
+// the decoder rather than converted.
+func implementsUnmarshaler(v reflect.Value) bool {
+	if !v.CanAddr() {
+		return false
+	}
+	switch v.Addr().Interface().(type) {
+	case json.Unmarshaler, encoding.TextUnmarshaler:
+		return true
+	}
+	return false
+}
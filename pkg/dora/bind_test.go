@@ -0,0 +1,256 @@
+package dora
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testBindPet struct {
+	Name string `dora:"$.dog_name"`
+}
+
+type testBindUser struct {
+	Email     string       `dora:"$.email"`
+	Age       int          `dora:"$.age"`
+	Confirmed bool         `dora:"$.confirmed"`
+	Allergies *string      `dora:"$.allergies"`
+	Pet       *testBindPet `dora:"$.random_items[1]"`
+}
+
+type testBindCodes struct {
+	First  uint16  `dora:"$.codes[0]"`
+	Last   float64 `dora:"$.codes[4]"`
+	AsText string  `dora:"$.codes[4]"`
+}
+
+type testBindConfig struct {
+	testBindCodes
+	User     testBindUser  `dora:"$.data.users[0]"`
+	Nested   string        `dora:"$.superNest.inner1.inner2.inner3.inner4[0].inner5.inner6"`
+	PI       float32       `dora:"$.PI"`
+	Started  time.Time     `dora:"$.started"`
+	Timeout  time.Duration `dora:"$.timeout"`
+	Retry    time.Duration `dora:"$.retry"`
+	Missing  string        `dora:"$.not_here,optional"`
+	Codes    []float64     `dora:"$.codes"`
+	Max      uint64        `dora:"$.max"`
+	Skipped  string        `dora:"-"`
+	Untagged string
+}
+
+const testBindJSON = `{
+	"data": {
+		"users": [{
+			"email": "brad@example.com",
+			"confirmed": true,
+			"allergies": null,
+			"age": 30,
+			"random_items": [true, { "dog_name": "ellie" }]
+		}]
+	},
+	"codes": [200, 201, 400, 403, 404.567],
+	"superNest": { "inner1": { "inner2": { "inner3": { "inner4": [{ "inner5": { "inner6": "neato" } }] } } } },
+	"PI": 3.1415,
+	"started": "2020-04-19T10:30:00Z",
+	"timeout": "1m30s",
+	"retry": 5e8,
+	"max": 18446744073709551615
+}`
+
+func TestBind(t *testing.T) {
+	c, err := NewFromString(testBindJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	cfg := testBindConfig{Missing: "default", Untagged: "untouched"}
+	if err := Bind(c, &cfg); err != nil {
+		t.Fatalf("Failed to bind config. Error: %v", err)
+	}
+
+	expected := testBindConfig{
+		testBindCodes: testBindCodes{First: 200, Last: 404.567, AsText: "404.567"},
+		User: testBindUser{
+			Email:     "brad@example.com",
+			Age:       30,
+			Confirmed: true,
+			Pet:       &testBindPet{Name: "ellie"},
+		},
+		Nested:   "neato",
+		PI:       3.1415,
+		Started:  time.Date(2020, 4, 19, 10, 30, 0, 0, time.UTC),
+		Timeout:  90 * time.Second,
+		Retry:    500 * time.Millisecond,
+		Codes:    []float64{200, 201, 400, 403, 404.567},
+		Max:      18446744073709551615,
+		Missing:  "default",
+		Untagged: "untouched",
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Fatalf("Expected bound config %+v, got: %+v", expected, cfg)
+	}
+}
+
+func TestBindMissingPaths(t *testing.T) {
+	c, err := NewFromString(testBindJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	var cfg struct {
+		Name  string `dora:"$.name"`
+		Email string `dora:"$.data.users[0].email"`
+		User  struct {
+			Phone string `dora:"$.phone,required"`
+			Fax   string `dora:"$.fax,optional"`
+		} `dora:"$.data.users[0]"`
+		Age    bool   `dora:"$.data.users[0].age"`
+		Domain string `dora:"$.data.users[0].email.domain,optional"`
+		First  string `dora:"$.codes[*]"`
+	}
+	err = Bind(c, &cfg)
+
+	var missing *MissingPathsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Expected a *MissingPathsError, got: %v", err)
+	}
+	expectedPaths := []string{"$.name", "$.data.users[0].phone"}
+	if !reflect.DeepEqual(missing.Paths, expectedPaths) {
+		t.Fatalf("Expected missing paths %v, got: %v", expectedPaths, missing.Paths)
+	}
+
+	var typeErr *UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Path != "$.data.users[0].age" {
+		t.Fatalf("Expected an *UnmarshalTypeError for $.data.users[0].age, got: %v", err)
+	}
+	// Only paths that aren't in the document are missing, other failures are errors even when optional
+	if !errors.Is(err, ErrTypeMismatch) || !strings.Contains(err.Error(), "field Domain") {
+		t.Fatalf("Expected ErrTypeMismatch for a key asked of a string, got: %v", err)
+	}
+	if !errors.Is(err, ErrMultipleValues) || !strings.Contains(err.Error(), "field First") {
+		t.Fatalf("Expected ErrMultipleValues for a wildcard, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "field Age") {
+		t.Fatalf("Expected the error to name the field, got: %v", err)
+	}
+	if cfg.Email != "brad@example.com" {
+		t.Fatalf("Expected fields that exist to bind despite errors, got: %q", cfg.Email)
+	}
+}
+
+type testBindNode struct {
+	Email string        `dora:"$.data.users[0].email,optional"`
+	Next  *testBindNode // Untagged, so it would be bound with the same document forever
+	Child *testBindNode `dora:"$.data,optional"`
+	Self  *testBindNode `dora:"$,optional"` // Tagged with the same value, so it would also be bound forever
+}
+
+func TestBindRecursiveType(t *testing.T) {
+	c, err := NewFromString(testBindJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	var node testBindNode
+	if err := Bind(c, &node); err != nil {
+		t.Fatalf("Failed to bind a recursive type. Error: %v", err)
+	}
+	if node.Email != "brad@example.com" || node.Next != nil || node.Self != nil {
+		t.Fatalf("Expected the email bound and the fields binding the same value left alone, got: %+v", node)
+	}
+	// Tagged fields are bound relative to their value, so they stop when the document does
+	if node.Child == nil || node.Child.Email != "" || node.Child.Child != nil || node.Child.Self != nil {
+		t.Fatalf("Expected the tagged child bound relative to $.data, got: %+v", node.Child)
+	}
+}
+
+func TestBindUntaggedPointers(t *testing.T) {
+	c, err := NewFromString(testBindJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	var cfg struct {
+		Found *struct {
+			PI float64 `dora:"$.PI"`
+		}
+		NotFound *struct {
+			Fax string `dora:"$.fax,optional"`
+		}
+	}
+	if err := Bind(c, &cfg); err != nil {
+		t.Fatalf("Failed to bind untagged pointers. Error: %v", err)
+	}
+	if cfg.Found == nil || cfg.Found.PI != 3.1415 {
+		t.Fatalf("Expected the pointer with a bound field allocated, got: %+v", cfg.Found)
+	}
+	if cfg.NotFound != nil {
+		t.Fatalf("Expected the pointer with nothing to bind left nil, got: %+v", cfg.NotFound)
+	}
+}
+
+type testBindEmbeddedPet struct {
+	testBindPet // Unexported, but embedded structs are still bound
+}
+
+func TestBindUnexportedEmbedded(t *testing.T) {
+	c, err := NewFromString(testBindJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	var cfg struct {
+		Pet testBindEmbeddedPet `dora:"$.data.users[0].random_items[1]"`
+	}
+	if err := Bind(c, &cfg); err != nil {
+		t.Fatalf("Failed to bind an unexported embedded struct. Error: %v", err)
+	}
+	if cfg.Pet.Name != "ellie" {
+		t.Fatalf("Expected the embedded struct bound relative to the tagged value, got: %+v", cfg.Pet)
+	}
+}
+
+func TestBindExactIntegers(t *testing.T) {
+	c, err := NewFromString(`{"big": 9007199254740993.0, "exp": 1.8446744073709551615e19, "frac": 9007199254740993.5, "huge": 1e400}`)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	var cfg struct {
+		Big int64  `dora:"$.big"`
+		Exp uint64 `dora:"$.exp"`
+	}
+	if err := Bind(c, &cfg); err != nil {
+		t.Fatalf("Failed to bind integers. Error: %v", err)
+	}
+	if cfg.Big != 9007199254740993 || cfg.Exp != 18446744073709551615 {
+		t.Fatalf("Expected integers bound without rounding through float64, got: %+v", cfg)
+	}
+
+	// Numbers a float64 would round to a whole number are still rejected
+	var frac struct {
+		Frac int64  `dora:"$.frac"`
+		Huge uint64 `dora:"$.huge"`
+	}
+	err = Bind(c, &frac)
+	if err == nil || !strings.Contains(err.Error(), "field Frac") || !strings.Contains(err.Error(), "field Huge") {
+		t.Fatalf("Expected errors for a fraction and an overflow, got: %v", err)
+	}
+}
+
+func TestBindInvalidTarget(t *testing.T) {
+	c, err := NewFromString(testBindJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	var cfg testBindConfig
+	for _, v := range []any{cfg, (*testBindConfig)(nil), new(string)} {
+		if err := Bind(c, v); err == nil {
+			t.Fatalf("Expected an error binding into %T", v)
+		}
+	}
+}
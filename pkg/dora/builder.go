@@ -0,0 +1,62 @@
+package dora
+
+import (
+	"slices"
+
+	"github.com/bradford-hamilton/dora/pkg/jsonpath"
+)
+
+// Path builds a query one step at a time, ex: `dora.Root().Key("data").Index(0).Key(userKey)`. Keys
+// are escaped as they're written out, so any key, including one a user typed, selects exactly that
+// key. A Path is a value and each step returns a new one, so a shared prefix can be extended in
+// different directions.
+type Path struct {
+	segments []jsonpath.Segment
+}
+
+// Root returns the Path of the root value, `$`.
+func Root() Path {
+	return Path{}
+}
+
+// Key returns the path extended with a step selecting key from an object. Queries can only hold
+// valid UTF-8, so a path with a key that isn't can be printed but not compiled.
+func (p Path) Key(key string) Path {
+	return p.with(jsonpath.NameSelector{Name: key})
+}
+
+// Index returns the path extended with a step selecting index from an array. Negative indexes count
+// back from the end of the array.
+func (p Path) Index(index int) Path {
+	return p.with(jsonpath.IndexSelector{Index: index})
+}
+
+// with returns the path extended with a step holding sel. The segments are copied rather than appended
+// to in place, so paths sharing a prefix never see each other's steps.
+func (p Path) with(sel jsonpath.Selector) Path {
+	segments := append(p.segments[:len(p.segments):len(p.segments)], jsonpath.Segment{Selectors: []jsonpath.Selector{sel}})
+	return Path{segments: segments}
+}
+
+// String returns the path as a query in canonical form, ex: `$.data.users[0]['first-name']`.
+func (p Path) String() string {
+	return p.AST().String()
+}
+
+// AST returns the path as a query syntax tree.
+func (p Path) AST() *jsonpath.Query {
+	return &jsonpath.Query{Segments: slices.Clone(p.segments)}
+}
+
+// Compile compiles the path into a Query. It fails with ErrQuerySyntax when an index is larger than a
+// query allows, or a key isn't valid UTF-8.
+func (p Path) Compile() (*Query, error) {
+	return CompileAST(p.AST())
+}
+
+// Pointer returns the path as a JSON Pointer (RFC 6901), ex: `/data/users/0/first-name`. Pointers
+// can't count back from the end of an array, so paths with negative indexes are an error, as are paths
+// that don't compile.
+func (p Path) Pointer() (string, error) {
+	return PathToPointer(p.String())
+}
@@ -0,0 +1,86 @@
+package dora
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	c, err := NewFromString(testPointerJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	tests := [...]struct {
+		path     Path
+		query    string
+		pointer  string
+		expected string
+	}{
+		{path: Root(), query: "$", pointer: ""},
+		{path: Root().Key("foo").Index(1), query: "$.foo[1]", pointer: "/foo/1", expected: "baz"},
+		{path: Root().Key(""), query: "$['']", pointer: "/", expected: "0"},
+		{path: Root().Key("a/b"), query: "$['a/b']", pointer: "/a~1b", expected: "1"},
+		{path: Root().Key(`i\j`), query: `$['i\\j']`, pointer: `/i\j`, expected: "5"},
+		{path: Root().Key(`k"l`), query: `$['k"l']`, pointer: `/k"l`, expected: "6"},
+		{path: Root().Key("m~n"), query: "$['m~n']", pointer: "/m~0n", expected: "8"},
+		{path: Root().Key("0").Key("1").Index(0), query: "$['0']['1'][0]", pointer: "/0/1/0", expected: "x"},
+	}
+
+	for _, tt := range tests {
+		if s := tt.path.String(); s != tt.query {
+			t.Fatalf("Expected query %s, got: %s", tt.query, s)
+		}
+		pointer, err := tt.path.Pointer()
+		if err != nil {
+			t.Fatalf("Failed to convert %s to a pointer. Error: %v", tt.query, err)
+		}
+		if pointer != tt.pointer {
+			t.Fatalf("Expected %s to convert to %q, got: %q", tt.query, tt.pointer, pointer)
+		}
+		if tt.expected == "" {
+			continue
+		}
+		q, err := tt.path.Compile()
+		if err != nil {
+			t.Fatalf("Failed to compile %s. Error: %v", tt.query, err)
+		}
+		if result, err := q.Eval(c); err != nil || result != tt.expected {
+			t.Fatalf("Expected %s to evaluate to %s, got: %s, %v", tt.query, tt.expected, result, err)
+		}
+	}
+
+	// A key a user typed can't break out of its step
+	injected := Root().Key("foo'] ..* ['")
+	if s := injected.String(); s != `$['foo\'] ..* [\'']` {
+		t.Fatalf("Expected the key to be escaped, got: %s", s)
+	}
+	q, err := injected.Compile()
+	if err != nil {
+		t.Fatalf("Failed to compile the escaped key. Error: %v", err)
+	}
+	if _, err := q.Eval(c); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+
+	// Paths sharing a prefix don't see each other's steps
+	base := Root().Key("foo")
+	first, last := base.Index(0), base.Index(-1)
+	if base.String() != "$.foo" || first.String() != "$.foo[0]" || last.String() != "$.foo[-1]" {
+		t.Fatalf("Expected independent paths, got: %s, %s, %s", base, first, last)
+	}
+
+	if _, err := last.Pointer(); err == nil {
+		t.Fatalf("Expected an error converting a negative index to a pointer")
+	}
+	if _, err := Root().Index(1 << 60).Compile(); !errors.Is(err, ErrQuerySyntax) {
+		t.Fatalf("Expected ErrQuerySyntax for an index out of range, got: %v", err)
+	}
+	invalid := Root().Key("a\xffb")
+	if _, err := invalid.Compile(); !errors.Is(err, ErrQuerySyntax) {
+		t.Fatalf("Expected ErrQuerySyntax for a key that isn't valid UTF-8, got: %v", err)
+	}
+	if _, err := invalid.Pointer(); err == nil {
+		t.Fatalf("Expected an error converting a key that isn't valid UTF-8 to a pointer")
+	}
+}
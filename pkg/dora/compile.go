@@ -0,0 +1,73 @@
+package dora
+
+import (
+	"github.com/bradford-hamilton/dora/pkg/jsonpath"
+)
+
+// Query is a compiled dora query. Compiling parses a query once, so it can be evaluated against any
+// number of clients without being parsed again. A Query is immutable and safe to use from many
+// goroutines at once.
+type Query struct {
+	query    string
+	parsed   *jsonpath.Query
+	segments []segment
+}
+
+// Compile parses a query into a Query that can be evaluated against any client.
+func Compile(query string) (*Query, error) {
+	parsed, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return &Query{query: query, parsed: parsed, segments: compileSegments(parsed.Segments)}, nil
+}
+
+// CompileAST compiles a query built with the jsonpath package, ex: from the steps a user clicked
+// through. The query is printed in canonical form and compiled from that, so queries built in code are
+// checked the same way as written ones, and String returns the canonical form.
+func CompileAST(query *jsonpath.Query) (*Query, error) {
+	return Compile(query.String())
+}
+
+// MustCompile is like Compile but panics if the query can't be parsed. It simplifies safe
+// initialization of package level variables holding compiled queries.
+func MustCompile(query string) *Query {
+	q, err := Compile(query)
+	if err != nil {
+		panic("dora: Compile(" + query + "): " + err.Error())
+	}
+	return q
+}
+
+// String returns the source text the query was compiled from.
+func (q *Query) String() string {
+	return q.query
+}
+
+// AST returns the syntax tree the query was parsed into. It's shared by every use of the query, so it
+// must not be modified.
+func (q *Query) AST() *jsonpath.Query {
+	return q.parsed
+}
+
+// Eval evaluates the query against a client and returns the value it points at, formatted the way
+// GetString formats it. Queries that can match more than one value return ErrMultipleValues, use
+// EvalAll for those.
+func (q *Query) Eval(c *Client) (string, error) {
+	node, err := c.resolveQuery(q)
+	if err != nil {
+		return "", err
+	}
+	return c.resultFromValue(node), nil
+}
+
+// EvalAll evaluates the query against a client and returns every value it matches, in document
+// order, formatted the way GetAll formats them.
+func (q *Query) EvalAll(c *Client) []string {
+	nodes := c.resolveAll(q.segments)
+	results := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		results = append(results, c.resultFromValue(node))
+	}
+	return results
+}
@@ -0,0 +1,132 @@
+package dora
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/bradford-hamilton/dora/pkg/jsonpath"
+)
+
+func TestCompile(t *testing.T) {
+	clients := make([]*Client, 0, 2)
+	for _, doc := range []string{TestJSON, `{ "data": { "users": [{ "email": "ellie@example.com" }] } }`} {
+		c, err := NewFromString(doc)
+		if err != nil {
+			t.Fatalf("\nError creating client: %v\n", err)
+		}
+		clients = append(clients, c)
+	}
+
+	q, err := Compile("$.data.users[0].email")
+	if err != nil {
+		t.Fatalf("Failed to compile query. Error: %v", err)
+	}
+	if q.String() != "$.data.users[0].email" {
+		t.Fatalf("Expected String to return the query, got: %s", q.String())
+	}
+
+	for i, expected := range []string{"brad@example.com", "ellie@example.com"} {
+		result, err := q.Eval(clients[i])
+		if err != nil {
+			t.Fatalf("Failed to evaluate query. Error: %v", err)
+		}
+		if result != expected {
+			t.Fatalf("Expected %s, got: %s", expected, result)
+		}
+	}
+
+	all := MustCompile("$.codes[?(@ >= 400)]")
+	if _, err := all.Eval(clients[0]); !errors.Is(err, ErrMultipleValues) {
+		t.Fatalf("Expected ErrMultipleValues from Eval, got: %v", err)
+	}
+	if results := all.EvalAll(clients[0]); !reflect.DeepEqual(results, []string{"400", "403", "404.567000"}) {
+		t.Fatalf("Expected three codes from EvalAll, got: %q", results)
+	}
+	if results := all.EvalAll(clients[1]); len(results) != 0 {
+		t.Fatalf("Expected no codes from EvalAll, got: %q", results)
+	}
+
+	if _, err := Compile("data.users"); !errors.Is(err, ErrNoDollarSignRoot) {
+		t.Fatalf("Expected ErrNoDollarSignRoot, got: %v", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Expected MustCompile to panic on an invalid query")
+		}
+	}()
+	MustCompile("$[")
+}
+
+func TestCompileConcurrent(t *testing.T) {
+	c, err := NewFromString(TestJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+	queries := map[*Query][]string{
+		MustCompile("$.data.users[0].first_name"):                            {"bradford"},
+		MustCompile("$.superNest..inner6"):                                   {"neato"},
+		MustCompile("$.data.users[?(match(@.email, '.*@example.com'))].age"): {"30"},
+		MustCompile("$.codes[0, -1]"):                                        {"200", "404.567000"},
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8*len(queries))
+	for i := 0; i < 8; i++ {
+		for q, expected := range queries {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if results := q.EvalAll(c); !reflect.DeepEqual(results, expected) {
+					errs <- fmt.Errorf("expected %s to match %q, got: %q", q, expected, results)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func TestCompileAST(t *testing.T) {
+	c, err := NewFromString(TestJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	q := &jsonpath.Query{}
+	for _, key := range []string{"data", "users"} {
+		q.Segments = append(q.Segments, jsonpath.Segment{Selectors: []jsonpath.Selector{jsonpath.NameSelector{Name: key}}})
+	}
+	q.Segments = append(q.Segments,
+		jsonpath.Segment{Selectors: []jsonpath.Selector{jsonpath.IndexSelector{Index: 0}}},
+		jsonpath.Segment{Selectors: []jsonpath.Selector{jsonpath.NameSelector{Name: "email"}}},
+	)
+
+	compiled, err := CompileAST(q)
+	if err != nil {
+		t.Fatalf("Failed to compile query. Error: %v", err)
+	}
+	if compiled.String() != "$.data.users[0].email" {
+		t.Fatalf("Expected the canonical query, got: %s", compiled.String())
+	}
+	if result, err := compiled.Eval(c); err != nil || result != "brad@example.com" {
+		t.Fatalf("Expected brad@example.com, got: %s, %v", result, err)
+	}
+
+	parsed := MustCompile(`$[ "codes" ][?@ > 400]`).AST()
+	if parsed.String() != "$.codes[?@ > 400]" || parsed.Singular() {
+		t.Fatalf("Expected the parsed query's tree, got: %s", parsed)
+	}
+
+	invalid := &jsonpath.Query{Segments: []jsonpath.Segment{{Selectors: []jsonpath.Selector{
+		jsonpath.FilterSelector{Filter: jsonpath.FunctionCall{Name: "unknown"}},
+	}}}}
+	if _, err := CompileAST(invalid); !errors.Is(err, ErrQuerySyntax) {
+		t.Fatalf("Expected ErrQuerySyntax, got: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package dora
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// complianceSuite is a test file in the format of the JSONPath Compliance Test Suite
+// (https://github.com/jsonpath-standard/jsonpath-compliance-test-suite). Tests whose results depend on
+// the order of object members list every allowed order in Results and ResultsPaths instead of a single
+// Result and ResultPaths.
+type complianceSuite struct {
+	Tests []struct {
+		Name            string          `json:"name"`
+		Selector        string          `json:"selector"`
+		Document        json.RawMessage `json:"document"`
+		Result          []any           `json:"result"`
+		ResultPaths     []string        `json:"result_paths"`
+		Results         [][]any         `json:"results"`
+		ResultsPaths    [][]string      `json:"results_paths"`
+		InvalidSelector bool            `json:"invalid_selector"`
+	} `json:"tests"`
+}
+
+// TestCompliance runs our own RFC 9535 cases and the official compliance suite. The suite's cts.json
+// must be in testdata, which `make cts` fetches along with its license as cts.LICENSE. A missing suite
+// fails the test rather than being skipped, so compliance can't quietly go unchecked.
+func TestCompliance(t *testing.T) {
+	for _, file := range [...]string{"testdata/rfc9535.json", "testdata/cts.json"} {
+		t.Run(file, func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("Failed to read the compliance suite, `make cts` fetches the official one. Error: %v", err)
+			}
+			var suite complianceSuite
+			if err := json.Unmarshal(data, &suite); err != nil {
+				t.Fatalf("Failed to decode the compliance suite. Error: %v", err)
+			}
+			runComplianceSuite(t, suite)
+		})
+	}
+}
+
+func runComplianceSuite(t *testing.T, suite complianceSuite) {
+	for _, tt := range suite.Tests {
+		if tt.InvalidSelector {
+			if _, err := scanQuery([]byte(tt.Selector)); err == nil {
+				t.Fatalf("%s: expected an error parsing %q", tt.Name, tt.Selector)
+			}
+			continue
+		}
+
+		// The document is parsed as written, so object members keep their order
+		c, err := NewFromBytes(tt.Document)
+		if err != nil {
+			t.Fatalf("%s: failed to create client. Error: %v", tt.Name, err)
+		}
+
+		q, err := Compile(tt.Selector)
+		if err != nil {
+			t.Fatalf("%s: failed to parse %q. Error: %v", tt.Name, tt.Selector, err)
+		}
+		results := []any{}
+		for _, node := range c.resolveAll(q.segments) {
+			value, err := nodeToValue(node, &valueOptions{})
+			if err != nil {
+				t.Fatalf("%s: failed to convert a result. Error: %v", tt.Name, err)
+			}
+			results = append(results, value)
+		}
+		expected := tt.Results
+		if expected == nil {
+			expected = [][]any{tt.Result}
+		}
+		if !matchesAny(results, expected) {
+			t.Fatalf("%s: expected %q to match one of %v, got: %v", tt.Name, tt.Selector, expected, results)
+		}
+
+		expectedPaths := tt.ResultsPaths
+		if expectedPaths == nil && tt.ResultPaths != nil {
+			expectedPaths = [][]string{tt.ResultPaths}
+		}
+		if expectedPaths == nil {
+			continue
+		}
+		paths, err := c.Paths(tt.Selector)
+		if err != nil {
+			t.Fatalf("%s: failed to get paths for %q. Error: %v", tt.Name, tt.Selector, err)
+		}
+		if !matchesAny(paths, expectedPaths) {
+			t.Fatalf("%s: expected %q to match one of the paths %q, got: %q", tt.Name, tt.Selector, expectedPaths, paths)
+		}
+	}
+}
+
+// matchesAny reports whether got is deeply equal to one of expected.
+func matchesAny[T any](got T, expected []T) bool {
+	for _, e := range expected {
+		if reflect.DeepEqual(got, e) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,593 @@
+package dora
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bradford-hamilton/dora/pkg/ast"
+	"github.com/bradford-hamilton/dora/pkg/jcs"
+)
+
+// UnmarshalTypeError describes a JSON value that can't be stored in a Go value of a specific type.
+// Path is the full query path of the value, so the problem can be found in the document.
+type UnmarshalTypeError struct {
+	Value string       // description of the JSON value, ex: "string" or "number 1.5"
+	Type  reflect.Type // type of Go value it could not be assigned to
+	Path  string       // path of the JSON value, ex: `$.data.users[0].age`
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return "cannot unmarshal " + e.Value + " into Go value of type " + e.Type.String() + " at " + e.Path
+}
+
+// GetInto resolves a query and stores the value it points at in the value pointed to by v, the
+// same way json.Unmarshal would. It works straight from the AST, without re-serializing the
+// subtree. `json` struct tags, embedded structs, pointers, slices, arrays, maps, and the
+// json.Unmarshaler and encoding.TextUnmarshaler interfaces are all supported. Decoding stops
+// at the first value that doesn't fit, returning an *UnmarshalTypeError with its full path.
+func (c *Client) GetInto(query string, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("GetInto requires a non-nil pointer, got: %T", v)
+	}
+	node, err := c.resolve(query)
+	if err != nil {
+		return err
+	}
+	d := decoder{input: c.input}
+	return d.decode(node, rv, []byte(query))
+}
+
+// decoder holds what's needed while decoding a subtree into Go values.
+type decoder struct {
+	input []byte
+}
+
+var (
+	numberType     = reflect.TypeFor[Number]()
+	jsonNumberType = reflect.TypeFor[json.Number]()
+)
+
+// decode stores node in v. The path is the query path of node, used for errors.
+func (d *decoder) decode(node ast.ValueContent, v reflect.Value, path []byte) error {
+	node = ast.Unwrap(node)
+	lit, isLiteral := node.(ast.Literal)
+	isNull := isLiteral && lit.ValueType == ast.NullLiteralValueType
+
+	u, tu, v := indirect(v, isNull)
+	if u != nil {
+		if err := u.UnmarshalJSON(d.raw(node)); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return nil
+	}
+	if tu != nil {
+		if !isLiteral || lit.ValueType != ast.StringLiteralValueType {
+			return typeError(node, v.Type(), path)
+		}
+		s, err := lit.Decoded()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if err := tu.UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return nil
+	}
+
+	switch n := node.(type) {
+	case ast.Object:
+		return d.decodeObject(n, v, path)
+	case ast.Array:
+		return d.decodeArray(n, v, path)
+	case ast.Literal:
+		return d.decodeLiteral(n, v, path)
+	default:
+		return fmt.Errorf("%s: unhandled node type %T", path, node)
+	}
+}
+
+func (d *decoder) decodeObject(obj ast.Object, v reflect.Value, path []byte) error {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return typeError(obj, v.Type(), path)
+		}
+		value, err := nodeToValue(obj, &valueOptions{})
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		v.Set(reflect.ValueOf(value))
+		return nil
+	case reflect.Map:
+		return d.decodeMap(obj, v, path)
+	case reflect.Struct:
+		fields := cachedFields(v.Type())
+		for _, prop := range obj.Children {
+			key, err := prop.Key.Decoded()
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			f := fields.lookup(key)
+			if f == nil {
+				continue // Like encoding/json, unknown keys are ignored
+			}
+			fv, ok := fieldByIndex(v, f.index)
+			if !ok {
+				continue
+			}
+			propPath := appendKeyToPath(path, key)
+			value := ast.Unwrap(prop.Value)
+			if f.quoted {
+				if value, err = unquoteLiteral(value); err != nil {
+					return typeError(value, fv.Type(), propPath)
+				}
+			}
+			if err := d.decode(value, fv, propPath); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return typeError(obj, v.Type(), path)
+	}
+}
+
+func (d *decoder) decodeMap(obj ast.Object, v reflect.Value, path []byte) error {
+	t := v.Type()
+	keyType := t.Key()
+	switch keyType.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		if !reflect.PointerTo(keyType).Implements(textUnmarshalerType) {
+			return typeError(obj, t, path)
+		}
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(t, len(obj.Children)))
+	}
+
+	for _, prop := range obj.Children {
+		key, err := prop.Key.Decoded()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		propPath := appendKeyToPath(path, key)
+
+		elem := reflect.New(t.Elem()).Elem()
+		if err := d.decode(prop.Value, elem, propPath); err != nil {
+			return err
+		}
+
+		kv := reflect.New(keyType).Elem()
+		switch {
+		case reflect.PointerTo(keyType).Implements(textUnmarshalerType):
+			if err := kv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key)); err != nil {
+				return fmt.Errorf("%s: %w", propPath, err)
+			}
+		case keyType.Kind() == reflect.String:
+			kv.SetString(key)
+		case kv.CanInt():
+			n, err := strconv.ParseInt(key, 10, 64)
+			if err != nil || kv.OverflowInt(n) {
+				return &UnmarshalTypeError{Value: "number " + key, Type: keyType, Path: string(propPath)}
+			}
+			kv.SetInt(n)
+		default:
+			n, err := strconv.ParseUint(key, 10, 64)
+			if err != nil || kv.OverflowUint(n) {
+				return &UnmarshalTypeError{Value: "number " + key, Type: keyType, Path: string(propPath)}
+			}
+			kv.SetUint(n)
+		}
+		v.SetMapIndex(kv, elem)
+	}
+	return nil
+}
+
+func (d *decoder) decodeArray(arr ast.Array, v reflect.Value, path []byte) error {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return typeError(arr, v.Type(), path)
+		}
+		value, err := nodeToValue(arr, &valueOptions{})
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		v.Set(reflect.ValueOf(value))
+		return nil
+	case reflect.Slice:
+		slice := reflect.MakeSlice(v.Type(), len(arr.Children), len(arr.Children))
+		for i, item := range arr.Children {
+			if err := d.decode(item.Value, slice.Index(i), appendIndexToPath(path, i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if i >= len(arr.Children) {
+				v.Index(i).SetZero() // Like encoding/json, extra Go array elements are zeroed
+				continue
+			}
+			if err := d.decode(arr.Children[i].Value, v.Index(i), appendIndexToPath(path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return typeError(arr, v.Type(), path)
+	}
+}
+
+func (d *decoder) decodeLiteral(lit ast.Literal, v reflect.Value, path []byte) error {
+	switch lit.ValueType {
+	case ast.NullLiteralValueType:
+		switch v.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice:
+			v.SetZero()
+		}
+		// Like encoding/json, null has no effect on other values
+		return nil
+	case ast.BooleanLiteralValueType:
+		b := lit.Value == true
+		switch {
+		case v.Kind() == reflect.Bool:
+			v.SetBool(b)
+		case v.Kind() == reflect.Interface && v.NumMethod() == 0:
+			v.Set(reflect.ValueOf(b))
+		default:
+			return typeError(lit, v.Type(), path)
+		}
+		return nil
+	case ast.StringLiteralValueType:
+		s, err := lit.Decoded()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		switch {
+		case v.Kind() == reflect.String:
+			if v.Type() == numberType || v.Type() == jsonNumberType {
+				return typeError(lit, v.Type(), path)
+			}
+			v.SetString(s)
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			// Like encoding/json, []byte is base64 encoded
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			v.SetBytes(b)
+		case v.Kind() == reflect.Interface && v.NumMethod() == 0:
+			v.Set(reflect.ValueOf(s))
+		default:
+			return typeError(lit, v.Type(), path)
+		}
+		return nil
+	case ast.NumberLiteralValueType:
+		num := lit.NumberText()
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(num, 10, 64)
+			if err != nil || v.OverflowInt(n) {
+				return typeError(lit, v.Type(), path)
+			}
+			v.SetInt(n)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			n, err := strconv.ParseUint(num, 10, 64)
+			if err != nil || v.OverflowUint(n) {
+				return typeError(lit, v.Type(), path)
+			}
+			v.SetUint(n)
+		case reflect.Float32, reflect.Float64:
+			f, err := strconv.ParseFloat(num, v.Type().Bits())
+			if err != nil || v.OverflowFloat(f) {
+				return typeError(lit, v.Type(), path)
+			}
+			v.SetFloat(f)
+		case reflect.String:
+			if v.Type() != numberType && v.Type() != jsonNumberType {
+				return typeError(lit, v.Type(), path)
+			}
+			v.SetString(num)
+		case reflect.Interface:
+			if v.NumMethod() != 0 {
+				return typeError(lit, v.Type(), path)
+			}
+			f, err := strconv.ParseFloat(num, 64)
+			if err != nil {
+				return typeError(lit, v.Type(), path)
+			}
+			v.Set(reflect.ValueOf(f))
+		default:
+			return typeError(lit, v.Type(), path)
+		}
+		return nil
+	default:
+		return typeError(lit, v.Type(), path)
+	}
+}
+
+// raw returns the JSON text of node for a json.Unmarshaler. The source text is used when it's
+// plain JSON, otherwise (ex: it has comments or single quotes) the node is written canonically.
+func (d *decoder) raw(node ast.ValueContent) []byte {
+	span := ast.SpanOf(node)
+	if span.Start.IsValid() && span.End.Offset <= len(d.input) {
+		if src := d.input[span.Start.Offset:span.End.Offset]; json.Valid(src) {
+			return src
+		}
+	}
+	if b, err := jcs.Marshal(node); err == nil {
+		return b
+	}
+	return []byte("null")
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// indirect walks down v through pointers and interfaces, allocating as needed, until it finds
+// a json.Unmarshaler, an encoding.TextUnmarshaler, or a value that isn't a pointer. When
+// decoding null, it stops at the last settable pointer so it can be set to nil.
+// This follows the function of the same name in encoding/json.
+func indirect(v reflect.Value, null bool) (json.Unmarshaler, encoding.TextUnmarshaler, reflect.Value) {
+	// Start from a pointer to v when we can, so methods with pointer receivers are found.
+	if v.Kind() != reflect.Pointer && v.Type().Name() != "" && v.CanAddr() {
+		v = v.Addr()
+	}
+	for {
+		// Load the value inside an interface when it's a non-nil pointer, so we decode into it.
+		if v.Kind() == reflect.Interface && !v.IsNil() {
+			e := v.Elem()
+			if e.Kind() == reflect.Pointer && !e.IsNil() && (!null || e.Elem().Kind() == reflect.Pointer) {
+				v = e
+				continue
+			}
+		}
+		if v.Kind() != reflect.Pointer {
+			break
+		}
+		if null && v.CanSet() {
+			break
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if v.Type().NumMethod() > 0 && v.CanInterface() {
+			if u, ok := v.Interface().(json.Unmarshaler); ok {
+				return u, nil, reflect.Value{}
+			}
+			if !null {
+				if tu, ok := v.Interface().(encoding.TextUnmarshaler); ok {
+					return nil, tu, reflect.Value{}
+				}
+			}
+		}
+		v = v.Elem()
+	}
+	return nil, nil, v
+}
+
+// typeError builds an *UnmarshalTypeError for node.
+func typeError(node ast.ValueContent, t reflect.Type, path []byte) error {
+	return &UnmarshalTypeError{Value: describeNode(node), Type: t, Path: string(path)}
+}
+
+// describeNode describes a node for error messages, ex: "object" or "number 1.5".
+func describeNode(node ast.ValueContent) string {
+	switch n := ast.Unwrap(node).(type) {
+	case ast.Object:
+		return "object"
+	case ast.Array:
+		return "array"
+	case ast.Literal:
+		switch n.ValueType {
+		case ast.StringLiteralValueType:
+			return "string"
+		case ast.NumberLiteralValueType:
+			return "number " + n.NumberText()
+		case ast.BooleanLiteralValueType:
+			return "bool"
+		default:
+			return "null"
+		}
+	default:
+		return fmt.Sprintf("%T", n)
+	}
+}
+
+// unquoteLiteral handles the `,string` tag option, where a number or boolean is wrapped in a
+// JSON string. It returns the literal held inside the string.
+func unquoteLiteral(node ast.ValueContent) (ast.ValueContent, error) {
+	lit, ok := node.(ast.Literal)
+	if !ok || lit.ValueType != ast.StringLiteralValueType {
+		return node, fmt.Errorf("expected a string")
+	}
+	s, err := lit.Decoded()
+	if err != nil {
+		return node, err
+	}
+	inner := ast.Literal{Type: ast.LiteralType, Start: lit.Start, End: lit.End}
+	switch s {
+	case "true", "false":
+		inner.ValueType, inner.Value = ast.BooleanLiteralValueType, s == "true"
+	case "null":
+		inner.ValueType, inner.Value = ast.NullLiteralValueType, "null"
+	default:
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return node, err
+		}
+		inner.ValueType, inner.Value, inner.OriginalRendering = ast.NumberLiteralValueType, s, s
+	}
+	return inner, nil
+}
+
+// structField is a struct field that can be decoded into, found by walking the struct and
+// its embedded structs.
+type structField struct {
+	name   string
+	index  []int
+	tagged bool
+	quoted bool
+}
+
+type structFields struct {
+	list   []structField
+	byName map[string]*structField
+}
+
+// lookup finds the field for a JSON key. Like encoding/json, an exact match is preferred, but
+// keys match case-insensitively.
+func (fs *structFields) lookup(key string) *structField {
+	if f, ok := fs.byName[key]; ok {
+		return f
+	}
+	for i := range fs.list {
+		if strings.EqualFold(fs.list[i].name, key) {
+			return &fs.list[i]
+		}
+	}
+	return nil
+}
+
+var fieldCache sync.Map // map[reflect.Type]*structFields
+
+func cachedFields(t reflect.Type) *structFields {
+	if fs, ok := fieldCache.Load(t); ok {
+		return fs.(*structFields)
+	}
+	fs, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return fs.(*structFields)
+}
+
+// typeFields returns the fields JSON should recognize for the given struct type. Fields of
+// embedded structs are promoted, following Go's visibility rules amended by JSON tags: the
+// shallowest field wins, and a tagged field wins over untagged fields at the same depth.
+func typeFields(t reflect.Type) *structFields {
+	type queued struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	var fields []structField
+	depthOf := map[string]int{}
+	current, next := []queued{}, []queued{{typ: t}}
+	visited := map[reflect.Type]bool{}
+
+	for depth := 0; len(next) > 0; depth++ {
+		current, next = next, nil
+		candidates := map[string][]structField{}
+
+		for _, q := range current {
+			if visited[q.typ] {
+				continue
+			}
+			visited[q.typ] = true
+
+			for i := 0; i < q.typ.NumField(); i++ {
+				sf := q.typ.Field(i)
+				ft := sf.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if sf.Anonymous {
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				index := append(append([]int{}, q.index...), i)
+
+				if name == "" && sf.Anonymous && ft.Kind() == reflect.Struct {
+					next = append(next, queued{typ: ft, index: index})
+					continue
+				}
+				if !sf.IsExported() {
+					continue
+				}
+
+				f := structField{name: name, index: index, tagged: name != ""}
+				if name == "" {
+					f.name = sf.Name
+				}
+				for _, opt := range strings.Split(opts, ",") {
+					if opt == "string" {
+						switch ft.Kind() {
+						case reflect.Bool, reflect.Float32, reflect.Float64,
+							reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+							reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+							f.quoted = true
+						}
+					}
+				}
+				candidates[f.name] = append(candidates[f.name], f)
+			}
+		}
+
+		for name, fs := range candidates {
+			if _, taken := depthOf[name]; taken {
+				continue // A shallower field already has this name
+			}
+			depthOf[name] = depth
+			if len(fs) == 1 {
+				fields = append(fields, fs[0])
+				continue
+			}
+			var tagged []structField
+			for _, f := range fs {
+				if f.tagged {
+					tagged = append(tagged, f)
+				}
+			}
+			if len(tagged) == 1 {
+				fields = append(fields, tagged[0])
+			}
+			// Otherwise the name is ambiguous and, like encoding/json, neither field is used.
+		}
+	}
+
+	// Keep fields in declaration order, so case-insensitive matches are deterministic
+	slices.SortFunc(fields, func(a, b structField) int {
+		return slices.Compare(a.index, b.index)
+	})
+
+	byName := make(map[string]*structField, len(fields))
+	for i := range fields {
+		byName[fields[i].name] = &fields[i]
+	}
+	return &structFields{list: fields, byName: byName}
+}
+
+// fieldByIndex returns the nested field of v at index, allocating nil embedded pointers along
+// the way. It reports false when it runs into a nil pointer to an unexported embedded struct.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
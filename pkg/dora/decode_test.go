@@ -0,0 +1,158 @@
+package dora
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testPet struct {
+	Name string `json:"dog_name"`
+}
+
+type testAudit struct {
+	Confirmed bool `json:"confirmed"`
+}
+
+type testUser struct {
+	testAudit
+	FirstName   string         `json:"first_name"`
+	LastName    *string        `json:"last_name"`
+	Email       string         // matched case-insensitively against "email"
+	Allergies   *string        `json:"allergies"`
+	Age         uint8          `json:"age"`
+	RandomItems []any          `json:"random_items"`
+	Ignored     string         `json:"-"`
+	Extra       map[string]int `json:"extra"`
+}
+
+type testCelsius float64
+
+func (c *testCelsius) UnmarshalJSON(b []byte) error {
+	var f float64
+	if err := json.Unmarshal(b, &f); err != nil {
+		return err
+	}
+	*c = testCelsius(f - 273.15)
+	return nil
+}
+
+func TestClient_GetInto(t *testing.T) {
+	c, err := NewFromString(TestJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	var user testUser
+	if err := c.GetInto("$.data.users[0]", &user); err != nil {
+		t.Fatalf("Failed to decode user. Error: %v", err)
+	}
+	lastName := "human"
+	expected := testUser{
+		testAudit:   testAudit{Confirmed: true},
+		FirstName:   "bradford",
+		LastName:    &lastName,
+		Email:       "brad@example.com",
+		Age:         30,
+		RandomItems: []any{true, map[string]any{"dog_name": "ellie"}},
+	}
+	if !reflect.DeepEqual(user, expected) {
+		t.Fatalf("Expected user of %+v, got: %+v", expected, user)
+	}
+
+	var pet testPet
+	if err := c.GetInto("$.data.users[0].random_items[1]", &pet); err != nil || pet.Name != "ellie" {
+		t.Fatalf("Expected pet named ellie, got: %+v (%v)", pet, err)
+	}
+
+	var firstCodes [3]int
+	if err := c.GetInto("$.codes", &firstCodes); err != nil || firstCodes != [3]int{200, 201, 400} {
+		t.Fatalf("Expected the first three codes, got: %v (%v)", firstCodes, err)
+	}
+	var codes []int
+	if err := c.GetInto("$.codes", &codes); err == nil {
+		t.Fatalf("Expected an error decoding 404.567 into an int")
+	}
+
+	var nested []map[string]map[string]string
+	if err := c.GetInto("$.superNest.inner1.inner2.inner3.inner4", &nested); err != nil || nested[0]["inner5"]["inner6"] != "neato" {
+		t.Fatalf("Expected nested maps to decode, got: %v (%v)", nested, err)
+	}
+}
+
+func TestClient_GetIntoInterfaces(t *testing.T) {
+	c, err := NewFromString(`{
+		"when": "2020-04-19T10:00:00Z",
+		"temps": { "kitchen": 293.15, "attic": 303.15 },
+		"codes": { "200": "ok", "404": "not found" },
+		"id": "12",
+		"data": "aGVsbG8=",
+	}`)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	var when time.Time
+	if err := c.GetInto("$.when", &when); err != nil || !when.Equal(time.Date(2020, 4, 19, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Expected a time.Time from encoding.TextUnmarshaler, got: %v (%v)", when, err)
+	}
+
+	var temps map[string]testCelsius
+	if err := c.GetInto("$.temps", &temps); err != nil {
+		t.Fatalf("Failed to decode with json.Unmarshaler. Error: %v", err)
+	}
+	if temps["kitchen"] < 19.99 || temps["kitchen"] > 20.01 {
+		t.Fatalf("Expected kitchen to be 20 degrees, got: %v", temps["kitchen"])
+	}
+
+	var codes map[int]string
+	if err := c.GetInto("$.codes", &codes); err != nil || codes[404] != "not found" {
+		t.Fatalf("Expected int keyed map, got: %v (%v)", codes, err)
+	}
+
+	var quoted struct {
+		ID   int    `json:"id,string"`
+		Data []byte `json:"data"`
+	}
+	if err := c.GetInto("$", &quoted); err != nil || quoted.ID != 12 || string(quoted.Data) != "hello" {
+		t.Fatalf("Expected ,string option and base64 bytes to decode, got: %+v (%v)", quoted, err)
+	}
+}
+
+func TestClient_GetIntoTypeErrors(t *testing.T) {
+	c, err := NewFromString(TestJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	var user struct {
+		RandomItems []struct {
+			DogName int `json:"dog_name"`
+		} `json:"random_items"`
+	}
+	err = c.GetInto("$.data.users[0]", &user)
+
+	var typeErr *UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("Expected an *UnmarshalTypeError, got: %v", err)
+	}
+	if typeErr.Path != "$.data.users[0].random_items[0]" {
+		t.Fatalf("Expected error at $.data.users[0].random_items[0], got: %s", typeErr.Path)
+	}
+	if typeErr.Value != "bool" {
+		t.Fatalf("Expected error for a bool value, got: %s", typeErr.Value)
+	}
+
+	var age int8
+	err = c.GetInto("$.data.users[0].first_name", &age)
+	if !errors.As(err, &typeErr) || !strings.Contains(err.Error(), "cannot unmarshal string into Go value of type int8 at $.data.users[0].first_name") {
+		t.Fatalf("Expected a type error with the full path, got: %v", err)
+	}
+
+	if err := c.GetInto("$.data", user); err == nil {
+		t.Fatalf("Expected an error for a non-pointer value")
+	}
+}
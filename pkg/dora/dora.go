@@ -2,22 +2,22 @@
 package dora
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/bradford-hamilton/dora/pkg/ast"
+	"github.com/bradford-hamilton/dora/pkg/jcs"
 	"github.com/bradford-hamilton/dora/pkg/lexer"
 	"github.com/bradford-hamilton/dora/pkg/parser"
 )
 
-// Client represents a dora client. The client holds things like a copy of the input, the tree (the
-// parsed AST representation built with Go types), the user's query & parsed version of the query, and
-// a query result. Client exposes public methods which access this underlying data.
+// Client represents a dora client. The client holds a copy of the input and the tree (the parsed AST
+// representation built with Go types). Client exposes public methods which query this underlying data.
+// Queries don't modify the client, so it's safe to use from many goroutines at once.
 type Client struct {
-	input       []byte
-	tree        *ast.RootNode
-	query       []byte
-	parsedQuery []queryToken
-	result      string
+	input []byte
+	tree  *ast.RootNode
 }
 
 // NewFromString takes a string, creates a lexer, creates a parser from the lexer,
@@ -55,7 +55,7 @@ func (c *Client) GetBool(query string) (bool, error) {
 	}
 	s, err := strconv.ParseBool(res)
 	if err != nil {
-		return false, err
+		return false, valueError(query, fmt.Errorf("%w: %v", ErrTypeMismatch, err))
 	}
 	return s, nil
 }
@@ -68,7 +68,121 @@ func (c *Client) GetFloat64(query string) (float64, error) {
 	}
 	f, err := strconv.ParseFloat(res, 64)
 	if err != nil {
-		return 0.0, err
+		return 0.0, valueError(query, fmt.Errorf("%w: %v", ErrTypeMismatch, err))
 	}
 	return f, nil
 }
+
+// GetAll returns every value a query matches, in document order, each formatted the way GetString
+// formats a single value. Queries can use wildcards to match more than one value: `.*` selects every
+// member of an object and `[*]` every element of an array, ex: `$.data.users[*].email`, and `..` applies
+// the selector after it at every depth of the document, ex: `$..email`. Slices, filters, and unions
+// like `[0, 2]` can match more than one value too. Values that a step of the query doesn't apply to
+// are skipped, so a query that matches nothing returns an empty slice.
+func (c *Client) GetAll(query string) ([]string, error) {
+	q, err := Compile(query)
+	if err != nil {
+		return nil, err
+	}
+	return q.EvalAll(c), nil
+}
+
+// Project runs a query whose last step picks keys from an object, ex: `$.data.users[0]['email', 'age']`,
+// and returns the picked members as a new JSON object, ex: `{"email":"brad@example.com","age":30}`.
+// Members are written in the order their keys are listed, with their values exactly as they appear
+// in the source, and keys the object doesn't have are left out. Keys, and values whose source isn't
+// valid JSON, like a single quoted string, are re-encoded, so the result is always valid JSON.
+func (c *Client) Project(query string) (string, error) {
+	q, err := Compile(query)
+	if err != nil {
+		return "", err
+	}
+	segments := q.segments
+	if len(segments) == 0 {
+		return "", &QueryError{Query: query, Pos: len(query), Err: ErrNotProjection}
+	}
+	last := segments[len(segments)-1]
+	if last.descendant {
+		return "", &QueryError{Query: query, Pos: last.pos, Err: ErrNotProjection}
+	}
+	keys := last.selectors
+	for _, sel := range keys {
+		if sel.kind != nameSelector {
+			return "", &QueryError{Query: query, Pos: last.pos, Err: ErrNotProjection}
+		}
+	}
+
+	node, err := c.resolveQuery(&Query{query: q.query, segments: segments[:len(segments)-1]})
+	if err != nil {
+		return "", err
+	}
+	obj, ok := node.(ast.Object)
+	if !ok {
+		err := fmt.Errorf("%w: a projection can only pick keys from an object, found a %s", ErrTypeMismatch, kindOf(node))
+		return "", &QueryError{Query: query, Pos: last.pos, Path: query[:last.pos], Err: err}
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	d := decoder{input: c.input}
+	seen := make(map[string]bool, len(keys))
+	for _, sel := range keys {
+		if seen[sel.name] {
+			continue
+		}
+		seen[sel.name] = true
+		for _, prop := range obj.Children {
+			if !keyMatches(prop.Key, sel.name) {
+				continue
+			}
+			// Keys are written the way JCS writes strings, since their source may be single quoted
+			key, err := jcs.Marshal(ast.Literal{Type: ast.LiteralType, ValueType: ast.StringLiteralValueType, Value: prop.Key.Value})
+			if err != nil {
+				return "", err
+			}
+			if b.Len() > 1 {
+				b.WriteByte(',')
+			}
+			b.Write(key)
+			b.WriteByte(':')
+			b.Write(d.raw(ast.Unwrap(prop.Value)))
+			break
+		}
+	}
+	b.WriteByte('}')
+	return b.String(), nil
+}
+
+// Locate resolves a query and returns the source range of the value it points at. The
+// span's positions carry byte offsets as well as lines and columns, which makes it easy
+// to point at the exact spot in the original document when reporting problems.
+func (c *Client) Locate(query string) (ast.Span, error) {
+	node, err := c.resolve(query)
+	if err != nil {
+		return ast.Span{}, err
+	}
+	return ast.SpanOf(node), nil
+}
+
+// Equal reports whether the client's document is semantically equal to other's, ignoring
+// formatting, comments, and number spelling. By default key order is ignored and array order
+// matters, see ast.KeyOrder and ast.ArrayOrder to change that.
+func (c *Client) Equal(other *Client, opts ...ast.EqualOption) bool {
+	return ast.Equal(*c.tree, *other.tree, opts...)
+}
+
+// Hash resolves a query and returns the semantic hash of the value it points at. Values that
+// are Equal have the same hash, see ast.Hash.
+func (c *Client) Hash(query string, opts ...ast.EqualOption) (uint64, error) {
+	node, err := c.resolve(query)
+	if err != nil {
+		return 0, err
+	}
+	return ast.Hash(node, opts...), nil
+}
+
+// Canonical returns the client's document serialized with the JSON Canonicalization Scheme
+// (RFC 8785). The output is deterministic, which makes it suitable for signing and hashing.
+func (c *Client) Canonical() ([]byte, error) {
+	return jcs.Marshal(*c.tree)
+}
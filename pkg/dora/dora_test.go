@@ -2,8 +2,13 @@ package dora
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/bradford-hamilton/dora/pkg/ast"
 )
 
 const TestJSON = `
@@ -35,72 +40,141 @@ const TestJSON = `
 	"disabled": false
 }`
 
-func TestScanQueryTokens(t *testing.T) {
+func TestScanQuery(t *testing.T) {
+	name := func(key string) selector { return selector{kind: nameSelector, name: key} }
+	index := func(i int) selector { return selector{kind: indexSelector, index: i} }
+	wildcard := selector{kind: wildcardSelector}
+	child := func(selectors ...selector) segment { return segment{selectors: selectors} }
+	descendant := func(selectors ...selector) segment { return segment{descendant: true, selectors: selectors} }
+
 	tests := [...]struct {
-		input         []byte
-		expectedToken []queryToken
+		input            []byte
+		expectedSegments []segment
 	}{
 		{
-			input: []byte("$.item1[2].innerKey"),
-			expectedToken: []queryToken{
-				{accessType: ObjectAccess, key: "item1"},
-				{accessType: ArrayAccess, index: 2},
-				{accessType: ObjectAccess, key: "innerKey"},
-			},
+			input:            []byte("$.item1[2].innerKey"),
+			expectedSegments: []segment{child(name("item1")), child(index(2)), child(name("innerKey"))},
 		},
 		{
-			input: []byte("$[25].item3"),
-			expectedToken: []queryToken{
-				{accessType: ArrayAccess, index: 25},
-				{accessType: ObjectAccess, key: "item3"},
-			},
+			input:            []byte("$[25].item3"),
+			expectedSegments: []segment{child(index(25)), child(name("item3"))},
 		},
 		{
-			input: []byte("$[7].item4.innerKey"),
-			expectedToken: []queryToken{
-				{accessType: ArrayAccess, index: 7},
-				{accessType: ObjectAccess, key: "item4"},
-				{accessType: ObjectAccess, key: "innerKey"},
-			},
+			input:            []byte("$[7].item4.innerKey"),
+			expectedSegments: []segment{child(index(7)), child(name("item4")), child(name("innerKey"))},
 		},
 		{
 			input: []byte("$.item1[2].innerKey.anotherValue"),
-			expectedToken: []queryToken{
-				{accessType: ObjectAccess, key: "item1"},
-				{accessType: ArrayAccess, index: 2},
-				{accessType: ObjectAccess, key: "innerKey"},
-				{accessType: ObjectAccess, key: "anotherValue"},
+			expectedSegments: []segment{
+				child(name("item1")), child(index(2)), child(name("innerKey")), child(name("anotherValue")),
 			},
 		},
 		{
 			input: []byte("$[0].item1[2].coolKey.neatValue[16]"),
-			expectedToken: []queryToken{
-				{accessType: ArrayAccess, index: 0},
-				{accessType: ObjectAccess, key: "item1"},
-				{accessType: ArrayAccess, index: 2},
-				{accessType: ObjectAccess, key: "coolKey"},
-				{accessType: ObjectAccess, key: "neatValue"},
-				{accessType: ArrayAccess, index: 16},
+			expectedSegments: []segment{
+				child(index(0)), child(name("item1")), child(index(2)),
+				child(name("coolKey")), child(name("neatValue")), child(index(16)),
 			},
 		},
+		{
+			input:            []byte("$.users[*].emails.*"),
+			expectedSegments: []segment{child(name("users")), child(wildcard), child(name("emails")), child(wildcard)},
+		},
+		{
+			input:            []byte("$..users[0]..*"),
+			expectedSegments: []segment{descendant(name("users")), child(index(0)), descendant(wildcard)},
+		},
+		{
+			input:            []byte("$.a..[*]..[1]"),
+			expectedSegments: []segment{child(name("a")), descendant(wildcard), descendant(index(1))},
+		},
+		{
+			input: []byte("$[-1][1:3][::-2][:]"),
+			expectedSegments: []segment{
+				child(index(-1)),
+				child(selector{kind: sliceSelector, slice: &slice{start: 1, end: 3, hasStart: true, hasEnd: true, step: 1}}),
+				child(selector{kind: sliceSelector, slice: &slice{step: -2}}),
+				child(selector{kind: sliceSelector, slice: &slice{step: 1}}),
+			},
+		},
+		{
+			input:            []byte(`$['first-name']["a.b"]..['it\'s \u00e9']`),
+			expectedSegments: []segment{child(name("first-name")), child(name("a.b")), descendant(name("it's é"))},
+		},
+		{
+			input:            []byte("$[*][0]"),
+			expectedSegments: []segment{child(wildcard), child(index(0))},
+		},
+		{
+			input: []byte("$['a', 'b'][0, -1, *]..['c',1:]"),
+			expectedSegments: []segment{
+				child(name("a"), name("b")),
+				child(index(0), index(-1), wildcard),
+				descendant(name("c"), selector{kind: sliceSelector, slice: &slice{start: 1, hasStart: true, step: 1}}),
+			},
+		},
+		{
+			input:            []byte("$ .a\n\t[ 0 ]  ..b .ü"),
+			expectedSegments: []segment{child(name("a")), child(index(0)), descendant(name("b")), child(name("ü"))},
+		},
+		{
+			input:            []byte("$"),
+			expectedSegments: nil,
+		},
 	}
 
 	for _, tt := range tests {
-		tokens, err := scanQueryTokens(tt.input)
+		segments, err := scanQuery(tt.input)
 		if err != nil {
-			t.Fatalf("Failed to scan tokens. Error: %v", err)
+			t.Fatalf("Failed to scan query %s. Error: %v", tt.input, err)
 		}
+		// Positions are checked by TestQueryError
+		for i := range segments {
+			segments[i].pos = 0
+		}
+		if !reflect.DeepEqual(segments, tt.expectedSegments) {
+			t.Fatalf("Expected segments of %+v for %s, got: %+v", tt.expectedSegments, tt.input, segments)
+		}
+	}
+}
 
-		for i, tok := range tokens {
-			if tok.accessType != tt.expectedToken[i].accessType {
-				t.Fatalf("Expected access type of %d, got: %d", tt.expectedToken[i].accessType, tok.accessType)
-			}
-			if tok.key != tt.expectedToken[i].key {
-				t.Fatalf("Expected key of %s, got: %s", tt.expectedToken[i].key, tok.key)
-			}
-			if tok.index != tt.expectedToken[i].index {
-				t.Fatalf("Expected index of %d, got: %d", tt.expectedToken[i].index, tok.index)
-			}
+func TestScanQueryErrors(t *testing.T) {
+	tests := [...]string{
+		"",
+		"item",
+		"$.",
+		"$.1a",
+		"$.a.",
+		"$a",
+		"$[",
+		"$[]",
+		"$[0,]",
+		"$[01]",
+		"$[-0]",
+		"$[9007199254740992]",
+		"$[1:2:3:4]",
+		"$['a]",
+		"$['\\x']",
+		"$['\\\"']",
+		"$...a",
+		"$.a ",
+		"$[?@.a == [1]]",
+		"$[?@.* == 1]",
+		"$[?length(@.*) == 1]",
+		"$[?length(@.a)]",
+		"$[?count(@.a) == 1 == 1]",
+		"$[?match(@.a, 'a') == true]",
+		"$[?unknown(@.a)]",
+		"$[?count(1) == 1]",
+		"$[?length(@.a, @.b) == 1]",
+		"$[?length() == 1]",
+		"$[?01 == 1]",
+		"$[?@.a == 1.]",
+	}
+
+	for _, query := range tests {
+		if _, err := scanQuery([]byte(query)); err == nil {
+			t.Fatalf("Expected an error scanning query %q", query)
 		}
 	}
 }
@@ -239,6 +313,595 @@ func TestClient_GetFloat64(t *testing.T) {
 	}
 }
 
+func TestClient_GetAll(t *testing.T) {
+	c, err := NewFromString(`{
+		"users": [
+			{ "email": "brad@example.com", "tags": ["a", "b"] },
+			{ "name": "no email" },
+			{ "email": "ellie@example.com", "tags": [] }
+		],
+		"config": { "debug": true, "retries": 3, "hosts": ["a.example.com"] }
+	}`)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	tests := [...]struct {
+		query    string
+		expected []string
+	}{
+		{query: "$.users[*].email", expected: []string{"brad@example.com", "ellie@example.com"}},
+		{query: "$.users[*].tags[*]", expected: []string{"a", "b"}},
+		{query: "$.config.*", expected: []string{"true", "3", `["a.example.com"]`}},
+		{query: "$.users[*].tags[0]", expected: []string{"a"}},
+		{query: "$.*.debug", expected: []string{"true"}},
+		{query: "$.users[1].name", expected: []string{"no email"}},
+		{query: "$.users[*].missing", expected: []string{}},
+		{query: "$..email", expected: []string{"brad@example.com", "ellie@example.com"}},
+		{query: "$..tags[0]", expected: []string{"a"}},
+		{query: "$..[0]", expected: []string{`{ "email": "brad@example.com", "tags": ["a", "b"] }`, "a", "a.example.com"}},
+		{query: "$.config..*", expected: []string{"true", "3", `["a.example.com"]`, "a.example.com"}},
+	}
+
+	for _, tt := range tests {
+		results, err := c.GetAll(tt.query)
+		if err != nil {
+			t.Fatalf("Failed to run query %s. Error: %v", tt.query, err)
+		}
+		if !reflect.DeepEqual(results, tt.expected) {
+			t.Fatalf("Expected %s to match %q, got: %q", tt.query, tt.expected, results)
+		}
+	}
+
+	for _, query := range []string{"$.users[*].email", "$..email"} {
+		if _, err := c.GetString(query); !errors.Is(err, ErrMultipleValues) {
+			t.Fatalf("Expected ErrMultipleValues from GetString(%s), got: %v", query, err)
+		}
+	}
+	for _, query := range []string{"$..", "$...email", "$.users..", "$..*..."} {
+		if _, err := c.GetAll(query); err == nil {
+			t.Fatalf("Expected an error from GetAll(%s)", query)
+		}
+	}
+}
+
+func TestClient_GetAllSlices(t *testing.T) {
+	c, err := NewFromString(`["a", "b", "c", "d", "e", "f", "g"]`)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	tests := [...]struct {
+		query    string
+		expected []string
+	}{
+		{query: "$[1:3]", expected: []string{"b", "c"}},
+		{query: "$[5:]", expected: []string{"f", "g"}},
+		{query: "$[1:5:2]", expected: []string{"b", "d"}},
+		{query: "$[5:1:-2]", expected: []string{"f", "d"}},
+		{query: "$[::-1]", expected: []string{"g", "f", "e", "d", "c", "b", "a"}},
+		{query: "$[-2:]", expected: []string{"f", "g"}},
+		{query: "$[:-5]", expected: []string{"a", "b"}},
+		{query: "$[-100:100:3]", expected: []string{"a", "d", "g"}},
+		{query: "$[0:0]", expected: []string{}},
+		{query: "$[::0]", expected: []string{}},
+		{query: "$[-1]", expected: []string{"g"}},
+		{query: "$[7]", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		results, err := c.GetAll(tt.query)
+		if err != nil {
+			t.Fatalf("Failed to run query %s. Error: %v", tt.query, err)
+		}
+		if !reflect.DeepEqual(results, tt.expected) {
+			t.Fatalf("Expected %s to match %q, got: %q", tt.query, tt.expected, results)
+		}
+	}
+
+	for _, query := range []string{"$[01]", "$[-0]", "$[1:2:3:4]", "$[1-]", "$[1", "$[:a]"} {
+		if _, err := c.GetAll(query); err == nil {
+			t.Fatalf("Expected an error from GetAll(%s)", query)
+		}
+	}
+}
+
+func TestClient_GetIndexes(t *testing.T) {
+	c, err := NewFromString(TestJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	if result, err := c.GetString("$.codes[-1]"); err != nil || result != "404.567000" {
+		t.Fatalf("Expected $.codes[-1] to be 404.567000, got: %s, %v", result, err)
+	}
+	if result, err := c.GetString("$.codes[-5]"); err != nil || result != "200" {
+		t.Fatalf("Expected $.codes[-5] to be 200, got: %s, %v", result, err)
+	}
+	for _, query := range []string{"$.codes[5]", "$.codes[99]", "$.codes[-6]"} {
+		if _, err := c.GetString(query); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Fatalf("Expected ErrIndexOutOfRange from GetString(%s), got: %v", query, err)
+		}
+	}
+	if _, err := c.GetString("$.codes[1:2]"); !errors.Is(err, ErrMultipleValues) {
+		t.Fatalf("Expected ErrMultipleValues from GetString with a slice, got: %v", err)
+	}
+}
+
+func TestClient_GetQuotedKeys(t *testing.T) {
+	c, err := NewFromString(`{
+		"first-name": "bradford",
+		"a.b": { "@id": 1, "it's": "quoted", "ключ": "key" },
+		"\u0061b": "escaped key",
+		"items": [{ "@id": 2 }]
+	}`)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	tests := [...]struct {
+		query    string
+		expected string
+	}{
+		{query: "$['first-name']", expected: "bradford"},
+		{query: `$["first-name"]`, expected: "bradford"},
+		{query: `$["a.b"]['@id']`, expected: "1"},
+		{query: `$['a.b']['it\'s']`, expected: "quoted"},
+		{query: `$['a.b']["it's"]`, expected: "quoted"},
+		{query: `$['a.b']['ключ']`, expected: "key"},
+		{query: `$['a.b']['\u043a\u043b\u044e\u0447']`, expected: "key"},
+		{query: "$.ab", expected: "escaped key"},
+		{query: "$.items[0]['@id']", expected: "2"},
+	}
+
+	for _, tt := range tests {
+		result, err := c.GetString(tt.query)
+		if err != nil {
+			t.Fatalf("Failed to run query %s. Error: %v", tt.query, err)
+		}
+		if result != tt.expected {
+			t.Fatalf("Expected %s to be %s, got: %s", tt.query, tt.expected, result)
+		}
+	}
+
+	results, err := c.GetAll("$..['@id']")
+	if err != nil || !reflect.DeepEqual(results, []string{"1", "2"}) {
+		t.Fatalf("Expected $..['@id'] to match [1 2], got: %q, %v", results, err)
+	}
+
+	// Paths to keys that aren't identifiers use bracket notation, and point back at the same value
+	input := `{ "a.b": { "it's\tx": true } }`
+	c, err = NewFromString(input)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+	info, err := c.PathAt(strings.Index(input, "true"))
+	if err != nil {
+		t.Fatalf("Failed to find path. Error: %v", err)
+	}
+	if expected := `$['a.b']['it\'s\tx']`; info.Path != expected {
+		t.Fatalf("Expected path of %s, got: %s", expected, info.Path)
+	}
+	if result, err := c.GetString(info.Path); err != nil || result != "true" {
+		t.Fatalf("Expected %s to be true, got: %s, %v", info.Path, result, err)
+	}
+
+	for _, query := range []string{"$['first-name'", "$['first-name]", `$['first-name"]`, `$['\x']`} {
+		if _, err := c.GetString(query); err == nil {
+			t.Fatalf("Expected an error from GetString(%s)", query)
+		}
+	}
+}
+
+func TestClient_GetAllFilters(t *testing.T) {
+	c, err := NewFromString(`{
+		"minAge": 21,
+		"users": [
+			{ "name": "bradford", "age": 30, "confirmed": true, "tags": ["admin"], "address": { "city": "Denver" } },
+			{ "name": "ellie", "age": 4, "confirmed": true, "tags": [] },
+			{ "name": "sam", "age": 21, "confirmed": false, "email": null },
+			{ "name": "o'neil", "age": 25.0, "confirmed": true, "address": { "city": "Boulder" } }
+		],
+		"settings": { "a": 1, "b": "two", "c": [1] }
+	}`)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	tests := [...]struct {
+		query    string
+		expected []string
+	}{
+		{query: "$.users[?(@.age > 21 && @.confirmed == true)].name", expected: []string{"bradford", "o'neil"}},
+		{query: "$.users[?@.age >= 21].name", expected: []string{"bradford", "sam", "o'neil"}},
+		{query: "$.users[?(@.age < 21 || @.confirmed == false)].name", expected: []string{"ellie", "sam"}},
+		{query: "$.users[?(@.age == $.minAge)].name", expected: []string{"sam"}},
+		{query: "$.users[?(@.age == 25)].name", expected: []string{"o'neil"}},
+		{query: "$.users[?(@.email)].name", expected: []string{"sam"}},
+		{query: "$.users[?(@.email == null)].name", expected: []string{"sam"}},
+		{query: "$.users[?(!@.address)].name", expected: []string{"ellie", "sam"}},
+		{query: "$.users[?(!(@.age > 10))].name", expected: []string{"ellie"}},
+		{query: "$.users[?(@.name == 'o\\'neil')].age", expected: []string{"25.000000"}},
+		{query: `$.users[?(@.name > "c" && @.name < "p")].name`, expected: []string{"ellie", "o'neil"}},
+		{query: "$.users[?(@.address.city == 'Denver')].name", expected: []string{"bradford"}},
+		{query: "$.users[?(@.tags[0] == 'admin')].name", expected: []string{"bradford"}},
+		{query: "$.users[?(@.missing == @.alsoMissing)].name", expected: []string{"bradford", "ellie", "sam", "o'neil"}},
+		{query: "$.users[?(@.missing < 1)].name", expected: []string{}},
+		{query: "$.users[?(@.confirmed < true)].name", expected: []string{}},
+		{query: "$.users[?(@.address[?(@ == 'Boulder')])].name", expected: []string{"o'neil"}},
+		{query: "$.settings[?(@ == 1)]", expected: []string{"1"}},
+		{query: "$..[?(@.city)].city", expected: []string{"Denver", "Boulder"}},
+		{query: "$.users[?(@.age > 1e1)].name", expected: []string{"bradford", "sam", "o'neil"}},
+	}
+
+	for _, tt := range tests {
+		results, err := c.GetAll(tt.query)
+		if err != nil {
+			t.Fatalf("Failed to run query %s. Error: %v", tt.query, err)
+		}
+		if !reflect.DeepEqual(results, tt.expected) {
+			t.Fatalf("Expected %s to match %q, got: %q", tt.query, tt.expected, results)
+		}
+	}
+
+	invalid := []string{
+		"$.users[?(@.age > )]",
+		"$.users[?(@.age > 21]",
+		"$.users[?(21)]",
+		"$.users[?(@.age = 21)]",
+		"$.users[?(@.tags[*] == 'admin')]",
+		"$.users[?(@.name == 'unterminated)]",
+		"$.users[?(@.age > 21) && ]",
+		"$.users[?(@.tags == [])]",
+	}
+	for _, query := range invalid {
+		if _, err := c.GetAll(query); err == nil {
+			t.Fatalf("Expected an error from GetAll(%s)", query)
+		}
+	}
+}
+
+func TestClient_GetAllUnions(t *testing.T) {
+	c, err := NewFromString(TestJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	tests := [...]struct {
+		query    string
+		expected []string
+	}{
+		{query: "$.codes[0,2,4]", expected: []string{"200", "400", "404.567000"}},
+		{query: "$.codes[4,0]", expected: []string{"404.567000", "200"}},
+		{query: "$.codes[-1, 0:2]", expected: []string{"404.567000", "200", "201"}},
+		{query: "$.data.users[0]['first_name', 'email', 'missing']", expected: []string{"bradford", "brad@example.com"}},
+		{query: `$["date", "PI"]`, expected: []string{"04/19/2020", "3.141500"}},
+		{query: "$.codes[1, *]", expected: []string{"201", "200", "201", "400", "403", "404.567000"}},
+		{query: "$.codes[0, 'name']", expected: []string{"200"}},
+	}
+
+	for _, tt := range tests {
+		results, err := c.GetAll(tt.query)
+		if err != nil {
+			t.Fatalf("Failed to run query %s. Error: %v", tt.query, err)
+		}
+		if !reflect.DeepEqual(results, tt.expected) {
+			t.Fatalf("Expected %s to match %q, got: %q", tt.query, tt.expected, results)
+		}
+	}
+
+	if _, err := c.GetString("$.codes[0,1]"); !errors.Is(err, ErrMultipleValues) {
+		t.Fatalf("Expected ErrMultipleValues from GetString with a union, got: %v", err)
+	}
+}
+
+func TestClient_Project(t *testing.T) {
+	c, err := NewFromString(TestJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	tests := [...]struct {
+		query    string
+		expected string
+	}{
+		{query: "$.data.users[0]['email', 'age']", expected: `{"email":"brad@example.com","age":30}`},
+		{query: "$.data.users[0]['age', 'email', 'age']", expected: `{"age":30,"email":"brad@example.com"}`},
+		{query: "$.data.users[0]['allergies', 'missing', 'random_items']", expected: `{"allergies":null,"random_items":[true, { "dog_name": "ellie" }]}`},
+		{query: "$['PI', 'disabled']", expected: `{"PI":3.1415,"disabled":false}`},
+		{query: "$.superNest.inner1.name", expected: `{}`},
+	}
+
+	for _, tt := range tests {
+		result, err := c.Project(tt.query)
+		if err != nil {
+			t.Fatalf("Failed to project %s. Error: %v", tt.query, err)
+		}
+		if result != tt.expected {
+			t.Fatalf("Expected %s to project %s, got: %s", tt.query, tt.expected, result)
+		}
+		if !json.Valid([]byte(result)) {
+			t.Fatalf("Expected %s to project valid JSON, got: %s", tt.query, result)
+		}
+	}
+
+	// Documents that aren't strict JSON still project valid JSON
+	loose, err := NewFromString(`{ 'it\'s': 'a \'quoted\' value', "tab\u0009": ['x', 1], "plain": { "a": "b" } }`)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+	result, err := loose.Project(`$["it's", 'tab\t', 'plain']`)
+	expected := `{"it's":"a 'quoted' value","tab\t":["x",1],"plain":{ "a": "b" }}`
+	if err != nil || result != expected {
+		t.Fatalf("Expected the projection %s, got: %s, %v", expected, result, err)
+	}
+	if !json.Valid([]byte(result)) {
+		t.Fatalf("Expected valid JSON, got: %s", result)
+	}
+
+	for _, query := range []string{"$", "$.codes[0, 1]", "$.data..['email']"} {
+		if _, err := c.Project(query); !errors.Is(err, ErrNotProjection) {
+			t.Fatalf("Expected ErrNotProjection from Project(%s), got: %v", query, err)
+		}
+	}
+	for _, query := range []string{"$.codes['a']", "$.data.users[*]['email']", "$.missing['a']"} {
+		if _, err := c.Project(query); err == nil {
+			t.Fatalf("Expected an error from Project(%s)", query)
+		}
+	}
+}
+
+func TestClient_Paths(t *testing.T) {
+	c, err := NewFromString(TestJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	tests := [...]struct {
+		query    string
+		expected []string
+	}{
+		{query: "$.codes[0, -1]", expected: []string{"$['codes'][0]", "$['codes'][4]"}},
+		{query: "$..dog_name", expected: []string{"$['data']['users'][0]['random_items'][1]['dog_name']"}},
+		{query: "$.data.users[?(length(@.random_items) == 2)].email", expected: []string{"$['data']['users'][0]['email']"}},
+		{query: "$.codes[?(@ > 400)]", expected: []string{"$['codes'][3]", "$['codes'][4]"}},
+		{query: "$.missing", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		paths, err := c.Paths(tt.query)
+		if err != nil {
+			t.Fatalf("Failed to get paths for %s. Error: %v", tt.query, err)
+		}
+		if !reflect.DeepEqual(paths, tt.expected) {
+			t.Fatalf("Expected %s to have paths %q, got: %q", tt.query, tt.expected, paths)
+		}
+	}
+}
+
+func TestClient_Locate(t *testing.T) {
+	tests := [...]struct {
+		query         string
+		expectedText  string
+		expectedStart string
+		expectedEnd   string
+	}{
+		{
+			query:         "$.data.users[0].first_name",
+			expectedText:  "\"bradford\"",
+			expectedStart: "5:18",
+			expectedEnd:   "5:28",
+		},
+		{
+			query:         "$.data.users[0].random_items[1]",
+			expectedText:  "{ \"dog_name\": \"ellie\" }",
+			expectedStart: "11:27",
+			expectedEnd:   "11:50",
+		},
+		{
+			query:         "$.codes[4]",
+			expectedText:  "404.567",
+			expectedStart: "14:32",
+			expectedEnd:   "14:39",
+		},
+		{
+			query:         "$.enabled",
+			expectedText:  "true",
+			expectedStart: "25:16",
+			expectedEnd:   "25:20",
+		},
+	}
+
+	for _, tt := range tests {
+		c, err := NewFromString(TestJSON)
+		if err != nil {
+			t.Fatalf("\nError creating client: %v\n", err)
+		}
+
+		span, err := c.Locate(tt.query)
+		if err != nil {
+			t.Fatalf("Failed to locate %s. Error: %v", tt.query, err)
+		}
+
+		if text := TestJSON[span.Start.Offset:span.End.Offset]; text != tt.expectedText {
+			t.Fatalf("Expected located text of %s, got: %s", tt.expectedText, text)
+		}
+		if span.Start.String() != tt.expectedStart {
+			t.Fatalf("Expected start of %s, got: %s", tt.expectedStart, span.Start)
+		}
+		if span.End.String() != tt.expectedEnd {
+			t.Fatalf("Expected end of %s, got: %s", tt.expectedEnd, span.End)
+		}
+	}
+}
+
+func TestClient_UnterminatedString(t *testing.T) {
+	for _, input := range []string{`["abcdef`, `{"a":"b`} {
+		if _, err := NewFromString(input); err == nil {
+			t.Fatalf("Expected an error creating a client for %s", input)
+		}
+	}
+}
+
+func TestClient_PathAt(t *testing.T) {
+	tests := [...]struct {
+		line           int
+		column         int
+		expectedPath   string
+		expectedTarget Target
+	}{
+		{line: 5, column: 4, expectedPath: "$.data.users[0].first_name", expectedTarget: TargetKey},
+		{line: 5, column: 20, expectedPath: "$.data.users[0].first_name", expectedTarget: TargetValue},
+		{line: 5, column: 17, expectedPath: "$.data.users[0].first_name", expectedTarget: TargetPunctuation},
+		{line: 11, column: 32, expectedPath: "$.data.users[0].random_items[1].dog_name", expectedTarget: TargetKey},
+		{line: 11, column: 25, expectedPath: "$.data.users[0].random_items", expectedTarget: TargetPunctuation},
+		{line: 14, column: 23, expectedPath: "$.codes[2]", expectedTarget: TargetValue},
+		{line: 3, column: 11, expectedPath: "$.data", expectedTarget: TargetPunctuation},
+		{line: 2, column: 1, expectedPath: "$", expectedTarget: TargetPunctuation},
+	}
+
+	c, err := NewFromString(TestJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	for _, tt := range tests {
+		info, err := c.PathAtPosition(tt.line, tt.column)
+		if err != nil {
+			t.Fatalf("Failed to find path at %d:%d. Error: %v", tt.line, tt.column, err)
+		}
+		if info.Path != tt.expectedPath {
+			t.Fatalf("Expected path of %s at %d:%d, got: %s", tt.expectedPath, tt.line, tt.column, info.Path)
+		}
+		if info.Target != tt.expectedTarget {
+			t.Fatalf("Expected target of %s at %d:%d, got: %s", tt.expectedTarget, tt.line, tt.column, info.Target)
+		}
+
+		// The path found should point right back at the same node
+		span, err := c.Locate(info.Path)
+		if err != nil {
+			t.Fatalf("Failed to locate %s. Error: %v", info.Path, err)
+		}
+		if span != info.Span {
+			t.Fatalf("Expected span of %s for %s, got: %s", span, info.Path, info.Span)
+		}
+	}
+
+	if _, err := c.PathAtPosition(1, 1); err != ErrPositionOutOfRange {
+		t.Fatalf("Expected ErrPositionOutOfRange before the root value, got: %v", err)
+	}
+	if _, err := c.PathAt(len(TestJSON) + 10); err != ErrPositionOutOfRange {
+		t.Fatalf("Expected ErrPositionOutOfRange past the end of the input, got: %v", err)
+	}
+}
+
+func TestClient_Equal(t *testing.T) {
+	c, err := NewFromString(`{ "name": "dora", "tags": ["a", "b"], "version": 1.0 }`)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+	other, err := NewFromString("{\n\t// reordered and reformatted\n\t\"version\": 1,\n\t\"tags\": [\"a\", \"b\"],\n\t\"name\": \"dora\"\n}")
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	if !c.Equal(other) {
+		t.Fatalf("Expected documents to be equal")
+	}
+	if c.Equal(other, ast.KeyOrder(true)) {
+		t.Fatalf("Expected documents not to be equal when key order matters")
+	}
+
+	h1, err := c.Hash("$.tags")
+	if err != nil {
+		t.Fatalf("Failed to hash. Error: %v", err)
+	}
+	h2, err := other.Hash("$.tags")
+	if err != nil {
+		t.Fatalf("Failed to hash. Error: %v", err)
+	}
+	if h1 != h2 {
+		t.Fatalf("Expected equal subtrees to have the same hash")
+	}
+}
+
+func TestClient_Value(t *testing.T) {
+	c, err := NewFromString(TestJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	user, err := c.Value("$.data.users[0]")
+	if err != nil {
+		t.Fatalf("Failed to get value. Error: %v", err)
+	}
+	expected := map[string]any{
+		"first_name":   "bradford",
+		"last_name":    "human",
+		"email":        "brad@example.com",
+		"confirmed":    true,
+		"allergies":    nil,
+		"age":          30.0,
+		"random_items": []any{true, map[string]any{"dog_name": "ellie"}},
+	}
+	if !reflect.DeepEqual(user, expected) {
+		t.Fatalf("Expected value of %v, got: %v", expected, user)
+	}
+
+	codes, err := c.Value("$.codes", UseNumber())
+	if err != nil {
+		t.Fatalf("Failed to get value. Error: %v", err)
+	}
+	expectedCodes := []any{Number("200"), Number("201"), Number("400"), Number("403"), Number("404.567")}
+	if !reflect.DeepEqual(codes, expectedCodes) {
+		t.Fatalf("Expected value of %v, got: %v", expectedCodes, codes)
+	}
+
+	escaped, err := NewFromString(`{ "quote": "say \"hi\"\n", "big": 12345678901234567890 }`)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+	quote, err := escaped.Value("$.quote")
+	if err != nil || quote != "say \"hi\"\n" {
+		t.Fatalf("Expected escapes to be decoded, got: %q (%v)", quote, err)
+	}
+	big, err := escaped.Value("$.big", UseNumber())
+	if err != nil || big != Number("12345678901234567890") {
+		t.Fatalf("Expected number to keep its spelling, got: %v (%v)", big, err)
+	}
+}
+
+func TestClient_OrderedValue(t *testing.T) {
+	c, err := NewFromString(`{ "zebra": 1, "apple": { "b": true, "a": null }, "mango": [1, 2] }`)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	v, err := c.OrderedValue("$")
+	if err != nil {
+		t.Fatalf("Failed to get value. Error: %v", err)
+	}
+	m, ok := v.(*OrderedMap)
+	if !ok {
+		t.Fatalf("Expected an *OrderedMap, got: %T", v)
+	}
+	if keys := m.Keys(); !reflect.DeepEqual(keys, []string{"zebra", "apple", "mango"}) {
+		t.Fatalf("Expected keys in source order, got: %v", keys)
+	}
+	apple, _ := m.Get("apple")
+	if keys := apple.(*OrderedMap).Keys(); !reflect.DeepEqual(keys, []string{"b", "a"}) {
+		t.Fatalf("Expected nested keys in source order, got: %v", keys)
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Failed to marshal. Error: %v", err)
+	}
+	if expected := `{"zebra":1,"apple":{"b":true,"a":null},"mango":[1,2]}`; string(b) != expected {
+		t.Fatalf("Expected JSON of %s, got: %s", expected, b)
+	}
+}
+
 // Most recent bench: (faster than std lib!!!!!!!!!!!!!)
 // goos: darwin
 // goarch: amd64
@@ -0,0 +1,253 @@
+package dora
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bradford-hamilton/dora/pkg/ast"
+)
+
+// Explanation is a step by step account of how a query was resolved, returned by Explain.
+type Explanation struct {
+	Query string        // The query that was explained
+	Steps []ExplainStep // One step for each segment of the query, up to the one that failed or matched nothing
+	Err   error         // Why the query failed, or nil if it resolved
+}
+
+// ExplainStep describes one step of a query, the value it was applied to, and what happened. A step
+// following one that matched more than one value is applied to each of them, and describes the first.
+type ExplainStep struct {
+	Segment     string   // The step as written in the query, ex: `.users` or `[0]`
+	Pos         int      // The byte offset of the step in the query
+	Path        string   // The path of the value the step was applied to, ex: `$.data`
+	Kind        Kind     // The kind of the value the step was applied to
+	Keys        []string // The keys of the value when it's an object, in source order
+	Len         int      // The number of members or elements when the value is an object or array
+	Values      int      // The number of values the step was applied to
+	Matches     int      // The number of values the step selected
+	Suggestions []string // Keys close to the one asked for, when the key wasn't found
+	Err         error    // Why the step failed, or nil if it succeeded
+}
+
+// Explain resolves a query step by step, recording each step, the kind of value it reached, and the
+// keys or length available there. When a key can't be found, the keys closest to it by edit distance
+// are suggested, which makes typos easy to spot. The query failing isn't an error, the failure is
+// recorded in the Explanation. Only queries that can't be parsed return an error.
+//
+// A step that can match more than one value, like a wildcard, fails the query the way it fails
+// GetString, but it's still traced: the steps after it are applied to every value it matched, the way
+// GetAll applies them, until one matches nothing. Only the first of those values is described, and
+// keys are only suggested for a step applied to a single value.
+func (c *Client) Explain(query string) (*Explanation, error) {
+	q, err := Compile(query)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Explanation{Query: query}
+	root := ast.Unwrap(c.tree.RootValue.Content)
+	current := []ast.ValueContent{root}
+	path := []byte{'$'} // The path of current[0]
+	for i, seg := range q.segments {
+		end := len(query)
+		if i+1 < len(q.segments) {
+			end = q.segments[i+1].pos
+		}
+		step := ExplainStep{
+			Segment: strings.TrimSpace(query[seg.pos:end]),
+			Pos:     seg.pos,
+			Path:    string(path),
+			Kind:    kindOf(current[0]),
+			Values:  len(current),
+		}
+		switch n := current[0].(type) {
+		case ast.Object:
+			step.Len = len(n.Children)
+			step.Keys = make([]string, 0, len(n.Children))
+			for _, prop := range n.Children {
+				key, err := prop.Key.Decoded()
+				if err != nil {
+					key = prop.Key.Value
+				}
+				step.Keys = append(step.Keys, key)
+			}
+		case ast.Array:
+			step.Len = len(n.Children)
+		}
+
+		if singular := isSingular([]segment{seg}); singular && len(current) == 1 {
+			next, nextPath, err := childOf(current[0], seg.selectors[0], slices.Clip(path))
+			if err != nil {
+				step.Err = err
+				if errors.Is(err, ErrKeyNotFound) {
+					step.Suggestions = suggestKeys(seg.selectors[0].name, step.Keys)
+				}
+			} else {
+				step.Matches = 1
+				current, path = []ast.ValueContent{next}, nextPath
+			}
+		} else {
+			var next []ast.ValueContent
+			for _, node := range current {
+				next = append(next, selectNodes(root, node, []segment{seg})...)
+			}
+			if !singular {
+				step.Err = ErrMultipleValues
+			}
+			step.Matches = len(next)
+			current = next
+			if len(next) > 0 {
+				path = []byte(pathAt(root, ast.SpanOf(next[0]).Start.Offset, []byte{'$'}).Path)
+			}
+		}
+
+		e.Steps = append(e.Steps, step)
+		if step.Err != nil && e.Err == nil {
+			e.Err = &QueryError{Query: query, Pos: seg.pos, Path: step.Path, Err: step.Err}
+		}
+		if step.Matches == 0 {
+			break
+		}
+	}
+
+	return e, nil
+}
+
+// String formats the explanation with a line for each step, and the error and suggestions of the step
+// that failed, ex:
+//
+//	$.data.usres[0]
+//	  .data   on $       object with 7 keys: data, codes, superNest, date, enabled, PI, disabled
+//	  .usres  on $.data  object with 1 key: users
+//	          Sorry, could not find a key with that value. Key: usres, did you mean users?
+func (e *Explanation) String() string {
+	var b strings.Builder
+	b.WriteString(e.Query)
+	b.WriteByte('\n')
+
+	segWidth, pathWidth := 0, 0
+	for _, step := range e.Steps {
+		segWidth = max(segWidth, len(step.Segment))
+		pathWidth = max(pathWidth, len(step.describePath()))
+	}
+	for _, step := range e.Steps {
+		fmt.Fprintf(&b, "  %-*s  %-*s  %s\n", segWidth, step.Segment, pathWidth, step.describePath(), step.describeValue())
+		if step.Err == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "  %-*s  %v", segWidth, "", step.Err)
+		if len(step.Suggestions) > 0 {
+			fmt.Fprintf(&b, ", did you mean %s?", strings.Join(step.Suggestions, " or "))
+		}
+		b.WriteByte('\n')
+	}
+	if e.Err == nil {
+		b.WriteString("  resolved\n")
+	}
+	return b.String()
+}
+
+// maxExplainedKeys is the number of keys an explanation lists for an object.
+const maxExplainedKeys = 10
+
+// describePath describes the values the step was applied to, ex: `on $.data.users[0] and 2 more`.
+func (s ExplainStep) describePath() string {
+	if s.Values > 1 {
+		return fmt.Sprintf("on %s and %d more", s.Path, s.Values-1)
+	}
+	return "on " + s.Path
+}
+
+// describeValue describes the value the step was applied to, and how many values the step selected
+// when it could select more than one.
+func (s ExplainStep) describeValue() string {
+	value := s.describeKind()
+	if s.Values > 1 || errors.Is(s.Err, ErrMultipleValues) {
+		noun := "values"
+		if s.Matches == 1 {
+			noun = "value"
+		}
+		value += fmt.Sprintf(", matched %d %s", s.Matches, noun)
+	}
+	return value
+}
+
+func (s ExplainStep) describeKind() string {
+	switch s.Kind {
+	case KindObject:
+		noun := "keys"
+		if s.Len == 1 {
+			noun = "key"
+		}
+		if s.Len == 0 {
+			return "object with no keys"
+		}
+		keys := strings.Join(s.Keys[:min(len(s.Keys), maxExplainedKeys)], ", ")
+		if len(s.Keys) > maxExplainedKeys {
+			keys += ", ..."
+		}
+		return fmt.Sprintf("object with %d %s: %s", s.Len, noun, keys)
+	case KindArray:
+		return fmt.Sprintf("array of length %d", s.Len)
+	default:
+		return s.Kind.String()
+	}
+}
+
+// maxSuggestions is the number of near-miss keys suggested for a key that wasn't found.
+const maxSuggestions = 3
+
+// suggestKeys returns the keys closest to key by edit distance, closest first. A key is only close when
+// it's within a third of its length of key, or a single edit for short keys.
+func suggestKeys(key string, keys []string) []string {
+	type candidate struct {
+		key      string
+		distance int
+	}
+	var candidates []candidate
+	for _, k := range keys {
+		distance := editDistance(key, k)
+		limit := max(1, max(len([]rune(key)), len([]rune(k)))/3)
+		if distance <= limit {
+			candidates = append(candidates, candidate{key: k, distance: distance})
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return a.distance - b.distance
+	})
+
+	suggestions := make([]string, 0, min(len(candidates), maxSuggestions))
+	for _, c := range candidates[:min(len(candidates), maxSuggestions)] {
+		suggestions = append(suggestions, c.key)
+	}
+	return suggestions
+}
+
+// editDistance returns the edit distance between a and b, counting in characters: the number of
+// insertions, deletions, substitutions, and swaps of neighbouring characters that turn one into the
+// other. Letters that differ only by case cost nothing, so `userName` is close to `username`.
+func editDistance(a, b string) int {
+	ra, rb := []rune(strings.ToLower(a)), []rune(strings.ToLower(b))
+	// Three rows of the distance matrix: two rows back is needed for swaps
+	prev2, prev, curr := make([]int, len(rb)+1), make([]int, len(rb)+1), make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(rb)]
+}
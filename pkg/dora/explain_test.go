@@ -0,0 +1,109 @@
+package dora
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClient_Explain(t *testing.T) {
+	c, err := NewFromString(TestJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	e, err := c.Explain("$.superNest.inner1.inner3")
+	if err != nil {
+		t.Fatalf("Failed to explain query. Error: %v", err)
+	}
+	if !errors.Is(e.Err, ErrKeyNotFound) {
+		t.Fatalf("Expected the explanation to fail with ErrKeyNotFound, got: %v", e.Err)
+	}
+	if len(e.Steps) != 3 {
+		t.Fatalf("Expected 3 steps, got: %d", len(e.Steps))
+	}
+	last := e.Steps[2]
+	if last.Segment != ".inner3" || last.Pos != 18 || last.Path != "$.superNest.inner1" || last.Kind != KindObject {
+		t.Fatalf("Unexpected last step: %+v", last)
+	}
+	if !reflect.DeepEqual(last.Keys, []string{"inner2"}) || last.Len != 1 || !reflect.DeepEqual(last.Suggestions, []string{"inner2"}) {
+		t.Fatalf("Expected the last step to suggest inner2, got: %+v", last)
+	}
+	if e.Steps[0].Err != nil || e.Steps[1].Err != nil || last.Err == nil {
+		t.Fatalf("Expected only the last step to fail")
+	}
+
+	tests := [...]struct {
+		query    string
+		steps    int
+		expected error
+		output   string
+	}{
+		{query: "$", steps: 0, output: "$\n  resolved\n"},
+		{query: "$.data.users[0].email", steps: 4},
+		{query: "$.data.usres[0]", steps: 2, expected: ErrKeyNotFound, output: "did you mean users?"},
+		{query: "$.Data", steps: 1, expected: ErrKeyNotFound, output: "did you mean data or date?"},
+		{query: "$.codes[ 9 ]", steps: 2, expected: ErrIndexOutOfRange, output: "  [ 9 ]   on $.codes  array of length 5\n"},
+		{query: "$.date.year", steps: 2, expected: ErrTypeMismatch, output: "  .year  on $.date  string\n"},
+		{query: "$.codes[*].x", steps: 3, expected: ErrMultipleValues, output: "  .x      on $.codes[0] and 4 more  number, matched 0 values\n"},
+		{query: "$.codes[10:]", steps: 2, expected: ErrMultipleValues, output: "array of length 5, matched 0 values\n"},
+		{query: "$.data.users[*].email", steps: 4, expected: ErrMultipleValues, output: "  .email  on $.data.users[0]  object with 7 keys"},
+		{query: "$..[?(@.dog_name)].dog_name", steps: 2, expected: ErrMultipleValues, output: "on $.data.users[0].random_items[1]"},
+		{query: "$.superNest.zzz", steps: 2, expected: ErrKeyNotFound, output: "Key: zzz\n"},
+	}
+
+	for _, tt := range tests {
+		e, err := c.Explain(tt.query)
+		if err != nil {
+			t.Fatalf("Failed to explain %s. Error: %v", tt.query, err)
+		}
+		if len(e.Steps) != tt.steps {
+			t.Fatalf("Expected %d steps explaining %s, got: %d", tt.steps, tt.query, len(e.Steps))
+		}
+		if tt.expected == nil && e.Err != nil || !errors.Is(e.Err, tt.expected) {
+			t.Fatalf("Expected explaining %s to fail with %v, got: %v", tt.query, tt.expected, e.Err)
+		}
+		if out := e.String(); !strings.Contains(out, tt.output) {
+			t.Fatalf("Expected the explanation of %s to contain %q, got:\n%s", tt.query, tt.output, out)
+		}
+	}
+
+	// Steps after a wildcard are traced through every value it matched
+	e, err = c.Explain("$.codes[*]")
+	if err != nil {
+		t.Fatalf("Failed to explain query. Error: %v", err)
+	}
+	var queryErr *QueryError
+	if !errors.As(e.Err, &queryErr) || queryErr.Pos != 7 || queryErr.Path != "$.codes" {
+		t.Fatalf("Expected the wildcard step to fail the query, got: %v", e.Err)
+	}
+	if wildcard := e.Steps[1]; wildcard.Values != 1 || wildcard.Matches != 5 || !errors.Is(wildcard.Err, ErrMultipleValues) {
+		t.Fatalf("Unexpected wildcard step: %+v", wildcard)
+	}
+
+	if _, err := c.Explain("$.data."); !errors.Is(err, ErrQuerySyntax) {
+		t.Fatalf("Expected ErrQuerySyntax, got: %v", err)
+	}
+}
+
+func TestSuggestKeys(t *testing.T) {
+	tests := [...]struct {
+		key      string
+		keys     []string
+		expected []string
+	}{
+		{key: "usres", keys: []string{"users", "codes"}, expected: []string{"users"}},
+		{key: "userName", keys: []string{"username", "user"}, expected: []string{"username"}},
+		{key: "emial", keys: []string{"email", "mail", "e-mail"}, expected: []string{"email", "e-mail"}},
+		{key: "first", keys: []string{"frist_name", "last"}, expected: []string{}},
+		{key: "a", keys: []string{"b", "ab", "abc"}, expected: []string{"b", "ab"}},
+		{key: "héllo", keys: []string{"hello"}, expected: []string{"hello"}},
+	}
+
+	for _, tt := range tests {
+		if suggestions := suggestKeys(tt.key, tt.keys); !reflect.DeepEqual(suggestions, tt.expected) {
+			t.Fatalf("Expected suggestions for %s to be %q, got: %q", tt.key, tt.expected, suggestions)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package dora
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/bradford-hamilton/dora/pkg/ast"
+	"github.com/bradford-hamilton/dora/pkg/jsonpath"
+)
+
+// filterExpr is a parsed filter expression, the part after the `?` in `[?(@.age > 21)]`. Filters are
+// evaluated once for every member of an object or element of an array, which is the current node `@`.
+type filterExpr interface {
+	eval(root, current ast.ValueContent) bool
+}
+
+// orExpr is true when any of its operands is true.
+type orExpr []filterExpr
+
+func (e orExpr) eval(root, current ast.ValueContent) bool {
+	for _, operand := range e {
+		if operand.eval(root, current) {
+			return true
+		}
+	}
+	return false
+}
+
+// andExpr is true when all of its operands are true.
+type andExpr []filterExpr
+
+func (e andExpr) eval(root, current ast.ValueContent) bool {
+	for _, operand := range e {
+		if !operand.eval(root, current) {
+			return false
+		}
+	}
+	return true
+}
+
+// notExpr negates the expression it holds.
+type notExpr struct {
+	expr filterExpr
+}
+
+func (e notExpr) eval(root, current ast.ValueContent) bool {
+	return !e.expr.eval(root, current)
+}
+
+// existsExpr is true when its query selects at least one node, ex: `[?(@.email)]`.
+type existsExpr struct {
+	query filterQuery
+}
+
+func (e existsExpr) eval(root, current ast.ValueContent) bool {
+	return len(e.query.selectNodes(root, current)) > 0
+}
+
+// comparisonExpr compares two values with one of the comparison operators.
+type comparisonExpr struct {
+	left, right filterOperand
+	op          string
+}
+
+func (e comparisonExpr) eval(root, current ast.ValueContent) bool {
+	left, right := e.left.value(root, current), e.right.value(root, current)
+
+	switch e.op {
+	case "==":
+		return valuesEqual(left, right)
+	case "!=":
+		return !valuesEqual(left, right)
+	case "<":
+		return valuesLess(left, right)
+	case "<=":
+		return valuesLess(left, right) || valuesEqual(left, right)
+	case ">":
+		return valuesLess(right, left)
+	case ">=":
+		return valuesLess(right, left) || valuesEqual(left, right)
+	default:
+		return false
+	}
+}
+
+// filterOperand is something that produces a single value: a literal, a singular query, or a function
+// returning a value. Its value is nil when there is none, ex: when a query selects nothing.
+type filterOperand interface {
+	value(root, current ast.ValueContent) ast.ValueContent
+}
+
+// literalOperand is a literal written in the filter, ex: `21` or `'bradford'`.
+type literalOperand struct {
+	lit ast.Literal
+}
+
+func (o literalOperand) value(root, current ast.ValueContent) ast.ValueContent {
+	return o.lit
+}
+
+// filterQuery is a query inside a filter, relative to the current node (`@`) or the root (`$`).
+type filterQuery struct {
+	relative bool
+	segments []segment
+}
+
+func (q filterQuery) selectNodes(root, current ast.ValueContent) []ast.ValueContent {
+	if q.relative {
+		return selectNodes(root, current, q.segments)
+	}
+	return selectNodes(root, root, q.segments)
+}
+
+func (q filterQuery) value(root, current ast.ValueContent) ast.ValueContent {
+	if nodes := q.selectNodes(root, current); len(nodes) == 1 {
+		return nodes[0]
+	}
+	return nil
+}
+
+// valuesEqual compares two values the way RFC 9535 does. Two missing values are equal, numbers are
+// equal when they have the same value however they're spelled, and objects and arrays are equal when
+// they hold equal values.
+func valuesEqual(a, b ast.ValueContent) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return ast.Equal(a, b)
+}
+
+// valuesLess reports whether a is less than b. Only numbers and strings are ordered, strings by their
+// Unicode code points, so comparing anything else with `<` is false.
+func valuesLess(a, b ast.ValueContent) bool {
+	la, ok := a.(ast.Literal)
+	if !ok {
+		return false
+	}
+	lb, ok := b.(ast.Literal)
+	if !ok || la.ValueType != lb.ValueType {
+		return false
+	}
+
+	switch la.ValueType {
+	case ast.NumberLiteralValueType:
+		fa, errA := strconv.ParseFloat(la.NumberText(), 64)
+		fb, errB := strconv.ParseFloat(lb.NumberText(), 64)
+		return errA == nil && errB == nil && fa < fb
+	case ast.StringLiteralValueType:
+		sa, errA := la.Decoded()
+		sb, errB := lb.Decoded()
+		return errA == nil && errB == nil && sa < sb
+	default:
+		return false
+	}
+}
+
+// compileFilter turns a parsed filter expression into one dora evaluates.
+func compileFilter(expr jsonpath.Expr) filterExpr {
+	switch e := expr.(type) {
+	case jsonpath.OrExpr:
+		operands := make(orExpr, 0, len(e.Operands))
+		for _, operand := range e.Operands {
+			operands = append(operands, compileFilter(operand))
+		}
+		return operands
+	case jsonpath.AndExpr:
+		operands := make(andExpr, 0, len(e.Operands))
+		for _, operand := range e.Operands {
+			operands = append(operands, compileFilter(operand))
+		}
+		return operands
+	case jsonpath.NotExpr:
+		return notExpr{expr: compileFilter(e.Expr)}
+	case jsonpath.ComparisonExpr:
+		return comparisonExpr{left: compileOperand(e.Left), right: compileOperand(e.Right), op: e.Op}
+	case jsonpath.FilterQuery:
+		return existsExpr{query: compileFilterQuery(e)}
+	case jsonpath.FunctionCall:
+		return functionTest{call: compileFunctionCall(e)}
+	default:
+		panic(fmt.Sprintf("dora: unexpected filter expression %T", expr))
+	}
+}
+
+// compileOperand turns a parsed literal, singular query, or function call returning a value into an operand.
+func compileOperand(expr jsonpath.Expr) filterOperand {
+	switch e := expr.(type) {
+	case jsonpath.Literal:
+		return literalOperand{lit: filterLiteral(e.Value)}
+	case jsonpath.FilterQuery:
+		return compileFilterQuery(e)
+	case jsonpath.FunctionCall:
+		return functionOperand{call: compileFunctionCall(e)}
+	default:
+		panic(fmt.Sprintf("dora: unexpected filter operand %T", expr))
+	}
+}
+
+func compileFilterQuery(q jsonpath.FilterQuery) filterQuery {
+	return filterQuery{relative: q.Relative, segments: compileSegments(q.Segments)}
+}
+
+// filterLiteral builds the literal node a literal written in a filter is compared as.
+func filterLiteral(value any) ast.Literal {
+	lit := ast.Literal{Type: ast.LiteralType, Value: value}
+	switch v := value.(type) {
+	case string:
+		// String literals hold their value the way it's written in JSON, which Decoded unescapes when comparing
+		quoted, _ := json.Marshal(v)
+		lit.ValueType = ast.StringLiteralValueType
+		lit.Value = string(quoted[1 : len(quoted)-1])
+		lit.Delimiter = `"`
+	case int64, float64:
+		lit.ValueType = ast.NumberLiteralValueType
+	case bool:
+		lit.ValueType = ast.BooleanLiteralValueType
+	default:
+		lit.ValueType = ast.NullLiteralValueType
+		lit.Value = "null"
+	}
+	return lit
+}
@@ -0,0 +1,466 @@
+package dora
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bradford-hamilton/dora/pkg/ast"
+	"github.com/bradford-hamilton/dora/pkg/jsonpath"
+)
+
+// functionValue is an argument to or a result from a function. Which field is used depends on its type.
+type functionValue struct {
+	value   ast.ValueContent   // for jsonpath.ValueType, nil when there is no value
+	logical bool               // for jsonpath.LogicalType
+	nodes   []ast.ValueContent // for jsonpath.NodesType
+}
+
+// functions are the implementations of the function extensions defined by RFC 9535 section 2.4. Their
+// signatures are kept by the jsonpath package, which checks calls as queries are parsed.
+var functions = map[string]func(args []functionValue) functionValue{
+	// length returns the number of characters in a string, elements in an array, or members in an object.
+	"length": func(args []functionValue) functionValue {
+		switch n := args[0].value.(type) {
+		case ast.Literal:
+			if s, err := n.Decoded(); err == nil && n.ValueType == ast.StringLiteralValueType {
+				return functionValue{value: numberLiteral(utf8.RuneCountInString(s))}
+			}
+		case ast.Array:
+			return functionValue{value: numberLiteral(len(n.Children))}
+		case ast.Object:
+			return functionValue{value: numberLiteral(len(n.Children))}
+		}
+		return functionValue{}
+	},
+	// count returns the number of nodes a query selects.
+	"count": func(args []functionValue) functionValue {
+		return functionValue{value: numberLiteral(len(args[0].nodes))}
+	},
+	// match reports whether an entire string matches a regular expression.
+	"match": func(args []functionValue) functionValue {
+		return functionValue{logical: patternMatches(args[0].value, args[1].value, true)}
+	},
+	// search reports whether any part of a string matches a regular expression.
+	"search": func(args []functionValue) functionValue {
+		return functionValue{logical: patternMatches(args[0].value, args[1].value, false)}
+	},
+	// value returns the value of the only node a query selects, or nothing when it selects more or less.
+	"value": func(args []functionValue) functionValue {
+		if len(args[0].nodes) == 1 {
+			return functionValue{value: args[0].nodes[0]}
+		}
+		return functionValue{}
+	},
+}
+
+// functionCall is a call to a function in a filter, ex: `length(@.name)`.
+type functionCall struct {
+	sig  jsonpath.Signature
+	call func(args []functionValue) functionValue
+	args []any // a filterOperand, filterQuery, or filterExpr, depending on the parameter's type
+}
+
+// regexpFunctions are the functions taking a regular expression as their second argument, and whether
+// they match the entire string.
+var regexpFunctions = map[string]bool{"match": true, "search": false}
+
+// compileFunctionCall turns a parsed function call into one dora evaluates. Queries passed for a
+// logical parameter are tests of whether they select anything. Regular expressions written as literals
+// are compiled here, once, rather than each time the filter is evaluated.
+func compileFunctionCall(call jsonpath.FunctionCall) functionCall {
+	sig, _ := jsonpath.Function(call.Name)
+	f := functionCall{sig: sig, call: functions[call.Name], args: make([]any, len(call.Args))}
+	for i, arg := range call.Args {
+		if sig.Params[i] == jsonpath.LogicalType {
+			f.args[i] = compileFilter(arg)
+		} else if query, ok := arg.(jsonpath.FilterQuery); ok {
+			f.args[i] = compileFilterQuery(query)
+		} else {
+			f.args[i] = compileOperand(arg)
+		}
+	}
+
+	if anchored, ok := regexpFunctions[call.Name]; ok {
+		if lit, ok := call.Args[1].(jsonpath.Literal); ok {
+			var re *regexp.Regexp
+			if pattern, ok := lit.Value.(string); ok {
+				re, _ = compileIRegexp(pattern, anchored)
+			}
+			f.call = func(args []functionValue) functionValue {
+				return functionValue{logical: regexpMatches(args[0].value, re)}
+			}
+		}
+	}
+	return f
+}
+
+func (f functionCall) evaluate(root, current ast.ValueContent) functionValue {
+	args := make([]functionValue, len(f.args))
+	for i, arg := range f.args {
+		switch a := arg.(type) {
+		case filterQuery:
+			if f.sig.Params[i] == jsonpath.NodesType {
+				args[i].nodes = a.selectNodes(root, current)
+			} else {
+				args[i].value = a.value(root, current)
+			}
+		case filterOperand:
+			args[i].value = a.value(root, current)
+		case filterExpr:
+			args[i].logical = a.eval(root, current)
+		}
+	}
+	return f.call(args)
+}
+
+// functionOperand is a function call used as a value, ex: `length(@.name) > 3`.
+type functionOperand struct {
+	call functionCall
+}
+
+func (o functionOperand) value(root, current ast.ValueContent) ast.ValueContent {
+	return o.call.evaluate(root, current).value
+}
+
+// functionTest is a function call used as an expression, ex: `match(@.date, '2020-.*')`.
+type functionTest struct {
+	call functionCall
+}
+
+func (t functionTest) eval(root, current ast.ValueContent) bool {
+	result := t.call.evaluate(root, current)
+	if t.call.sig.Result == jsonpath.NodesType {
+		return len(result.nodes) > 0
+	}
+	return result.logical
+}
+
+// numberLiteral returns n as a number literal.
+func numberLiteral(n int) ast.Literal {
+	return ast.Literal{
+		Type:              ast.LiteralType,
+		ValueType:         ast.NumberLiteralValueType,
+		Value:             int64(n),
+		OriginalRendering: strconv.Itoa(n),
+	}
+}
+
+// patternMatches reports whether the string s matches the I-Regexp (RFC 9485) pattern, which is compiled
+// each time since it comes from the document. Anything that isn't a string, and patterns that don't
+// compile, don't match.
+func patternMatches(s, pattern ast.ValueContent, anchored bool) bool {
+	pat, ok := decodedString(pattern)
+	if !ok {
+		return false
+	}
+	re, err := compileIRegexp(pat, anchored)
+	if err != nil {
+		return false
+	}
+	return regexpMatches(s, re)
+}
+
+// regexpMatches reports whether the string s matches re. Anything that isn't a string, and a nil re for a
+// pattern that didn't compile, don't match.
+func regexpMatches(s ast.ValueContent, re *regexp.Regexp) bool {
+	str, ok := decodedString(s)
+	return ok && re != nil && re.MatchString(str)
+}
+
+// compileIRegexp compiles an I-Regexp (RFC 9485) pattern as a Go regular expression. Patterns are
+// translated rather than passed through, since the two differ: `.` in I-Regexp matches anything but `\n`
+// and `\r`, where Go's only leaves out `\n`, I-Regexp has no anchors so `^` and `$` are ordinary
+// characters, and Go syntax I-Regexp doesn't have, like `\d` or `(?i)`, makes the pattern invalid.
+func compileIRegexp(pattern string, anchored bool) (*regexp.Regexp, error) {
+	t := iregexpTranslator{pattern: []rune(pattern)}
+	if anchored {
+		t.b.WriteString(`\A(?:`)
+	}
+	if err := t.branches(); err != nil {
+		return nil, err
+	}
+	if t.pos < len(t.pattern) {
+		return nil, t.errorf("unexpected %q", t.pattern[t.pos])
+	}
+	if anchored {
+		t.b.WriteString(`)\z`)
+	}
+	return regexp.Compile(t.b.String())
+}
+
+// iregexpTranslator checks a pattern against the I-Regexp grammar as it writes the equivalent Go regular
+// expression to b.
+type iregexpTranslator struct {
+	pattern []rune
+	pos     int
+	b       strings.Builder
+}
+
+func (t *iregexpTranslator) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid I-Regexp at offset %d: "+format, append([]any{t.pos}, args...)...)
+}
+
+// peek returns the next rune, or -1 at the end of the pattern.
+func (t *iregexpTranslator) peek() rune {
+	if t.pos < len(t.pattern) {
+		return t.pattern[t.pos]
+	}
+	return -1
+}
+
+// branches translates `branch *( "|" branch )`.
+func (t *iregexpTranslator) branches() error {
+	for {
+		if err := t.branch(); err != nil {
+			return err
+		}
+		if t.peek() != '|' {
+			return nil
+		}
+		t.pos++
+		t.b.WriteByte('|')
+	}
+}
+
+// branch translates a run of atoms, each optionally followed by a quantifier.
+func (t *iregexpTranslator) branch() error {
+	for {
+		switch char := t.peek(); {
+		case char == -1 || char == '|' || char == ')':
+			return nil
+		case char == '(':
+			t.pos++
+			t.b.WriteString("(?:")
+			if err := t.branches(); err != nil {
+				return err
+			}
+			if t.peek() != ')' {
+				return t.errorf("missing closing )")
+			}
+			t.pos++
+			t.b.WriteByte(')')
+		case char == '.':
+			t.pos++
+			t.b.WriteString(`[^\n\r]`)
+		case char == '[':
+			if err := t.classExpr(); err != nil {
+				return err
+			}
+		case char == '\\':
+			items, err := t.escape()
+			if err != nil {
+				return err
+			}
+			t.b.WriteString("[" + items + "]")
+		case isIRegexpNormalChar(char):
+			t.pos++
+			t.b.WriteString(regexp.QuoteMeta(string(char)))
+		default:
+			return t.errorf("unexpected %q", char)
+		}
+		if err := t.quantifier(); err != nil {
+			return err
+		}
+	}
+}
+
+// quantifier translates an optional `*`, `+`, `?`, `{n}`, `{n,}`, or `{n,m}`.
+func (t *iregexpTranslator) quantifier() error {
+	switch t.peek() {
+	case '*', '+', '?':
+		t.b.WriteRune(t.pattern[t.pos])
+		t.pos++
+		return nil
+	case '{':
+	default:
+		return nil
+	}
+
+	start := t.pos
+	t.pos++
+	if !t.digits() {
+		return t.errorf("expected a number")
+	}
+	if t.peek() == ',' {
+		t.pos++
+		t.digits()
+	}
+	if t.peek() != '}' {
+		return t.errorf("missing closing }")
+	}
+	t.pos++
+	t.b.WriteString(string(t.pattern[start:t.pos]))
+	return nil
+}
+
+// digits skips past a run of ASCII digits, and reports whether there were any.
+func (t *iregexpTranslator) digits() bool {
+	start := t.pos
+	for char := t.peek(); char >= '0' && char <= '9'; char = t.peek() {
+		t.pos++
+	}
+	return t.pos > start
+}
+
+// classExpr translates a character class, ex: `[^a-z\p{Lu}-]`.
+func (t *iregexpTranslator) classExpr() error {
+	t.pos++
+	t.b.WriteByte('[')
+	if t.peek() == '^' {
+		t.pos++
+		t.b.WriteByte('^')
+	}
+	if t.peek() == '-' {
+		t.pos++
+		t.b.WriteString(`\-`)
+	} else if err := t.classItem(); err != nil {
+		return err
+	}
+
+	for {
+		switch t.peek() {
+		case ']':
+			t.pos++
+			t.b.WriteByte(']')
+			return nil
+		case '-':
+			t.pos++
+			if t.peek() != ']' {
+				return t.errorf("- must be last in a class")
+			}
+			t.b.WriteString(`\-`)
+		case -1:
+			return t.errorf("missing closing ]")
+		default:
+			if err := t.classItem(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// classItem translates a character, a range of characters, or a category escape in a class.
+func (t *iregexpTranslator) classItem() error {
+	if t.peek() == '\\' && t.pos+1 < len(t.pattern) && strings.ContainsRune("pP", t.pattern[t.pos+1]) {
+		items, err := t.escape()
+		t.b.WriteString(items)
+		return err
+	}
+
+	if err := t.classChar(); err != nil {
+		return err
+	}
+	if t.peek() == '-' && t.pos+1 < len(t.pattern) && t.pattern[t.pos+1] != ']' {
+		t.pos++
+		t.b.WriteByte('-')
+		return t.classChar()
+	}
+	return nil
+}
+
+// classChar translates a single character in a class, which may be escaped.
+func (t *iregexpTranslator) classChar() error {
+	switch char := t.peek(); {
+	case char == '\\':
+		items, err := t.escape()
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(items, `\p`) || strings.HasPrefix(items, `\P`) {
+			return t.errorf("a category can't be part of a range")
+		}
+		t.b.WriteString(items)
+	case char == -1 || char == '-' || char == '[' || char == ']':
+		return t.errorf("unexpected %q in a class", char)
+	default:
+		t.pos++
+		t.b.WriteString(regexp.QuoteMeta(string(char)))
+	}
+	return nil
+}
+
+// escape translates an escape, ex: `\n`, `\.`, or `\p{Lu}`, into what it matches, written as the
+// inside of a Go character class.
+func (t *iregexpTranslator) escape() (string, error) {
+	t.pos++
+	char := t.peek()
+	t.pos++
+	switch char {
+	case 'n':
+		return `\n`, nil
+	case 'r':
+		return `\r`, nil
+	case 't':
+		return `\t`, nil
+	case '(', ')', '*', '+', '-', '.', '?', '[', '\\', ']', '^', '{', '|', '}':
+		return `\` + string(char), nil
+	case 'p', 'P':
+		if t.peek() != '{' {
+			return "", t.errorf("expected { after \\%c", char)
+		}
+		end := t.pos + 1
+		for end < len(t.pattern) && t.pattern[end] != '}' {
+			end++
+		}
+		if end == len(t.pattern) {
+			return "", t.errorf("missing closing }")
+		}
+		category := string(t.pattern[t.pos+1 : end])
+		t.pos = end + 1
+		items, ok := categoryClass(category, char == 'P')
+		if !ok {
+			return "", t.errorf("unknown category %q", category)
+		}
+		return items, nil
+	}
+	t.pos--
+	if char == -1 {
+		return "", t.errorf("trailing \\")
+	}
+	return "", t.errorf("unknown escape \\%c", char)
+}
+
+// iregexpCategories are the general categories I-Regexp allows in `\p{...}` and `\P{...}`.
+var iregexpCategories = map[string]bool{
+	"L": true, "Ll": true, "Lm": true, "Lo": true, "Lt": true, "Lu": true,
+	"M": true, "Mc": true, "Me": true, "Mn": true,
+	"N": true, "Nd": true, "Nl": true, "No": true,
+	"P": true, "Pc": true, "Pd": true, "Pe": true, "Pf": true, "Pi": true, "Po": true, "Ps": true,
+	"Z": true, "Zl": true, "Zp": true, "Zs": true,
+	"S": true, "Sc": true, "Sk": true, "Sm": true, "So": true,
+	"C": true, "Cc": true, "Cf": true, "Cn": true, "Co": true,
+}
+
+// categoryClass returns the characters in a general category, or outside it when negated, written as the
+// inside of a Go character class.
+func categoryClass(category string, negated bool) (string, bool) {
+	if !iregexpCategories[category] {
+		return "", false
+	}
+	if negated {
+		return `\P{` + category + `}`, true
+	}
+	return `\p{` + category + `}`, true
+}
+
+// isIRegexpNormalChar reports whether char matches itself in an I-Regexp, outside a class.
+func isIRegexpNormalChar(char rune) bool {
+	switch char {
+	case '(', ')', '*', '+', '.', '?', '[', '\\', ']', '{', '|', '}':
+		return false
+	}
+	return char < 0xD800 || char > 0xDFFF
+}
+
+// decodedString returns the decoded value of a string literal.
+func decodedString(node ast.ValueContent) (string, bool) {
+	lit, ok := node.(ast.Literal)
+	if !ok || lit.ValueType != ast.StringLiteralValueType {
+		return "", false
+	}
+	s, err := lit.Decoded()
+	return s, err == nil
+}
@@ -0,0 +1,220 @@
+package dora
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/bradford-hamilton/dora/pkg/ast"
+)
+
+// GetInt64 resolves a query and returns the number it points at as an int64. Numbers written with
+// a fraction or exponent are allowed as long as they're whole, ex: `1e3`, and anything that isn't a
+// number, or is a number an int64 can't hold, is an error.
+func (c *Client) GetInt64(query string) (int64, error) {
+	lit, err := c.literal(query, KindNumber)
+	if err != nil {
+		return 0, err
+	}
+	if n, ok := lit.Value.(int64); ok {
+		return n, nil
+	}
+	n, err := toInt(lit.NumberText())
+	if err != nil {
+		return 0, valueError(query, fmt.Errorf("%w: %v", ErrTypeMismatch, err))
+	}
+	return n, nil
+}
+
+// GetInt is the same as GetInt64, except it returns an int.
+func (c *Client) GetInt(query string) (int, error) {
+	n, err := c.GetInt64(query)
+	if err != nil {
+		return 0, err
+	}
+	if n < math.MinInt || n > math.MaxInt {
+		return 0, valueError(query, fmt.Errorf("%w: %d overflows int", ErrTypeMismatch, n))
+	}
+	return int(n), nil
+}
+
+// GetUint64 resolves a query and returns the number it points at as a uint64. Like GetInt64, the
+// number must be whole, and it can't be negative.
+func (c *Client) GetUint64(query string) (uint64, error) {
+	lit, err := c.literal(query, KindNumber)
+	if err != nil {
+		return 0, err
+	}
+	n, err := toUint(lit.NumberText())
+	if err != nil {
+		return 0, valueError(query, fmt.Errorf("%w: %v", ErrTypeMismatch, err))
+	}
+	return n, nil
+}
+
+// IsNull resolves a query and reports whether the value it points at is null.
+func (c *Client) IsNull(query string) (bool, error) {
+	kind, err := c.Kind(query)
+	if err != nil {
+		return false, err
+	}
+	return kind == KindNull, nil
+}
+
+// Exists reports whether a query matches at least one value. Unlike the getters, a query that can
+// match more than one value is fine, and only a query that can't be parsed is an error.
+func (c *Client) Exists(query string) (bool, error) {
+	q, err := Compile(query)
+	if err != nil {
+		return false, err
+	}
+	return len(c.resolveAll(q.segments)) > 0, nil
+}
+
+// Len resolves a query and returns the number of elements in the array, members in the object, or
+// characters in the string it points at.
+func (c *Client) Len(query string) (int, error) {
+	node, err := c.resolve(query)
+	if err != nil {
+		return 0, err
+	}
+	switch n := node.(type) {
+	case ast.Object:
+		return len(n.Children), nil
+	case ast.Array:
+		return len(n.Children), nil
+	case ast.Literal:
+		if n.ValueType == ast.StringLiteralValueType {
+			s, err := n.Decoded()
+			if err != nil {
+				return 0, valueError(query, err)
+			}
+			return utf8.RuneCountInString(s), nil
+		}
+	}
+	return 0, valueError(query, fmt.Errorf("%w: %s is %s, which has no length", ErrTypeMismatch, query, kindOf(node)))
+}
+
+// Keys resolves a query and returns the keys of the object it points at, in source order.
+func (c *Client) Keys(query string) ([]string, error) {
+	node, err := c.resolve(query)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := node.(ast.Object)
+	if !ok {
+		return nil, kindError(query, node, KindObject)
+	}
+	keys := make([]string, 0, len(obj.Children))
+	for _, prop := range obj.Children {
+		key, err := prop.Key.Decoded()
+		if err != nil {
+			return nil, valueError(query, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Kind resolves a query and returns the kind of the value it points at.
+func (c *Client) Kind(query string) (Kind, error) {
+	node, err := c.resolve(query)
+	if err != nil {
+		return 0, err
+	}
+	return kindOf(node), nil
+}
+
+// Get resolves a query and returns the value it points at as a T. Int64s, ints, and uint64s use the
+// matching typed getter, ex: GetInt64 for Get[int64], and strings, bools, and float64s must point at a
+// string, boolean, or number, so Get[string] returns a string's decoded value rather than formatting
+// any value the way GetString does. Any other type, like a struct, slice, or map, is decoded with
+// GetInto.
+func Get[T any](c *Client, query string) (T, error) {
+	var v T
+	var err error
+	switch p := any(&v).(type) {
+	case *string:
+		*p, err = c.stringValue(query)
+	case *bool:
+		*p, err = c.boolValue(query)
+	case *float64:
+		*p, err = c.float64Value(query)
+	case *int64:
+		*p, err = c.GetInt64(query)
+	case *int:
+		*p, err = c.GetInt(query)
+	case *uint64:
+		*p, err = c.GetUint64(query)
+	default:
+		err = c.GetInto(query, p)
+	}
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
+
+// GetOr is the same as Get, except it returns def when the query's path is missing from the document,
+// ex: a key the object doesn't have. Any other error, like a value of the wrong type, is still returned.
+func GetOr[T any](c *Client, query string, def T) (T, error) {
+	v, err := Get[T](c, query)
+	if errors.Is(err, ErrKeyNotFound) || errors.Is(err, ErrIndexOutOfRange) {
+		return def, nil
+	}
+	return v, err
+}
+
+// stringValue resolves a query that must point at a string, and returns the string decoded.
+func (c *Client) stringValue(query string) (string, error) {
+	lit, err := c.literal(query, KindString)
+	if err != nil {
+		return "", err
+	}
+	return lit.Decoded()
+}
+
+// boolValue resolves a query that must point at a boolean.
+func (c *Client) boolValue(query string) (bool, error) {
+	lit, err := c.literal(query, KindBool)
+	if err != nil {
+		return false, err
+	}
+	b, _ := lit.Value.(bool)
+	return b, nil
+}
+
+// float64Value resolves a query that must point at a number, and returns it as a float64.
+func (c *Client) float64Value(query string) (float64, error) {
+	lit, err := c.literal(query, KindNumber)
+	if err != nil {
+		return 0, err
+	}
+	if f, ok := lit.Value.(float64); ok {
+		return f, nil
+	}
+	f, err := strconv.ParseFloat(lit.NumberText(), 64)
+	if err != nil {
+		return 0, valueError(query, fmt.Errorf("%w: %v", ErrTypeMismatch, err))
+	}
+	return f, nil
+}
+
+// literal resolves a query that must point at a literal of the given kind.
+func (c *Client) literal(query string, kind Kind) (ast.Literal, error) {
+	node, err := c.resolve(query)
+	if err != nil {
+		return ast.Literal{}, err
+	}
+	if kindOf(node) != kind {
+		return ast.Literal{}, kindError(query, node, kind)
+	}
+	return node.(ast.Literal), nil
+}
+
+func kindError(query string, node ast.ValueContent, want Kind) error {
+	return valueError(query, fmt.Errorf("%w: %s is %s, not %s", ErrTypeMismatch, query, kindOf(node), want))
+}
@@ -0,0 +1,248 @@
+package dora
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const testGettersJSON = `{
+	"int": 42,
+	"negative": -7,
+	"whole": 1e3,
+	"fraction": 2.5,
+	"big": 9223372036854775807,
+	"huge": 18446744073709551615,
+	"string": "héllo",
+	"null": null,
+	"bool": true,
+	"array": [1, [2], {}],
+	"object": { "b": 1, "a": 2, "cé": 3 }
+}`
+
+func TestClient_GetInts(t *testing.T) {
+	c, err := NewFromString(testGettersJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	tests := [...]struct {
+		query      string
+		int64Value int64
+		int64Err   bool
+		uintValue  uint64
+		uintErr    bool
+	}{
+		{query: "$.int", int64Value: 42, uintValue: 42},
+		{query: "$.negative", int64Value: -7, uintErr: true},
+		{query: "$.whole", int64Value: 1000, uintValue: 1000},
+		{query: "$.fraction", int64Err: true, uintErr: true},
+		{query: "$.big", int64Value: 9223372036854775807, uintValue: 9223372036854775807},
+		{query: "$.huge", int64Err: true, uintValue: 18446744073709551615},
+		{query: "$.string", int64Err: true, uintErr: true},
+		{query: "$.null", int64Err: true, uintErr: true},
+		{query: "$.missing", int64Err: true, uintErr: true},
+	}
+
+	for _, tt := range tests {
+		n, err := c.GetInt64(tt.query)
+		if (err != nil) != tt.int64Err || n != tt.int64Value {
+			t.Fatalf("Expected GetInt64(%s) to return %d (error: %t), got: %d, %v", tt.query, tt.int64Value, tt.int64Err, n, err)
+		}
+		u, err := c.GetUint64(tt.query)
+		if (err != nil) != tt.uintErr || u != tt.uintValue {
+			t.Fatalf("Expected GetUint64(%s) to return %d (error: %t), got: %d, %v", tt.query, tt.uintValue, tt.uintErr, u, err)
+		}
+	}
+
+	if n, err := c.GetInt("$.array[0]"); err != nil || n != 1 {
+		t.Fatalf("Expected GetInt to return 1, got: %d, %v", n, err)
+	}
+	if _, err := c.GetInt64("$.string"); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("Expected ErrTypeMismatch from GetInt64 on a string, got: %v", err)
+	}
+	if _, err := c.GetInt64("$.missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("Expected ErrKeyNotFound from GetInt64 on a missing key, got: %v", err)
+	}
+}
+
+func TestClient_Inspect(t *testing.T) {
+	c, err := NewFromString(testGettersJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	kinds := map[string]Kind{
+		"$.int": KindNumber, "$.string": KindString, "$.null": KindNull, "$.bool": KindBool,
+		"$.array": KindArray, "$.object": KindObject,
+	}
+	for query, expected := range kinds {
+		kind, err := c.Kind(query)
+		if err != nil || kind != expected {
+			t.Fatalf("Expected Kind(%s) to be %s, got: %s, %v", query, expected, kind, err)
+		}
+		isNull, err := c.IsNull(query)
+		if err != nil || isNull != (expected == KindNull) {
+			t.Fatalf("Expected IsNull(%s) to be %t, got: %t, %v", query, expected == KindNull, isNull, err)
+		}
+	}
+	if _, err := c.IsNull("$.missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("Expected ErrKeyNotFound from IsNull on a missing key, got: %v", err)
+	}
+
+	exists := map[string]bool{
+		"$.null": true, "$.missing": false, "$.array[5]": false, "$.array[*]": true, "$..a": true, "$..missing": false,
+		"$.int.child": false,
+	}
+	for query, expected := range exists {
+		found, err := c.Exists(query)
+		if err != nil || found != expected {
+			t.Fatalf("Expected Exists(%s) to be %t, got: %t, %v", query, expected, found, err)
+		}
+	}
+	if _, err := c.Exists("$["); !errors.Is(err, ErrQuerySyntax) {
+		t.Fatalf("Expected ErrQuerySyntax from Exists, got: %v", err)
+	}
+
+	lengths := map[string]int{"$.array": 3, "$.object": 3, "$.string": 5, "$.array[2]": 0}
+	for query, expected := range lengths {
+		n, err := c.Len(query)
+		if err != nil || n != expected {
+			t.Fatalf("Expected Len(%s) to be %d, got: %d, %v", query, expected, n, err)
+		}
+	}
+	for _, query := range []string{"$.int", "$.null", "$.bool"} {
+		if _, err := c.Len(query); !errors.Is(err, ErrTypeMismatch) {
+			t.Fatalf("Expected ErrTypeMismatch from Len(%s), got: %v", query, err)
+		}
+	}
+
+	keys, err := c.Keys("$.object")
+	if err != nil || !reflect.DeepEqual(keys, []string{"b", "a", "cé"}) {
+		t.Fatalf("Expected keys in source order, got: %q, %v", keys, err)
+	}
+	if _, err := c.Keys("$.array"); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("Expected ErrTypeMismatch from Keys on an array, got: %v", err)
+	}
+}
+
+type testGetConfig struct {
+	Name string `json:"name"`
+	Port int    `json:"port"`
+}
+
+func TestGet(t *testing.T) {
+	c, err := NewFromString(`{
+		"name": "dora",
+		"port": 8080,
+		"ratio": 0.5,
+		"debug": true,
+		"motto": "say \"hi\"\n",
+		"enabled": "true",
+		"big": 9007199254740993,
+		"tags": ["a", "b"],
+		"limits": { "cpu": 2, "memory": 512 },
+		"servers": [{ "name": "one", "port": 1 }]
+	}`)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	if v, err := Get[string](c, "$.name"); err != nil || v != "dora" {
+		t.Fatalf("Expected dora, got: %v, %v", v, err)
+	}
+	if v, err := Get[int](c, "$.port"); err != nil || v != 8080 {
+		t.Fatalf("Expected 8080, got: %v, %v", v, err)
+	}
+	if v, err := Get[int64](c, "$.big"); err != nil || v != 9007199254740993 {
+		t.Fatalf("Expected 9007199254740993, got: %v, %v", v, err)
+	}
+	if v, err := Get[uint64](c, "$.port"); err != nil || v != 8080 {
+		t.Fatalf("Expected 8080, got: %v, %v", v, err)
+	}
+	if v, err := Get[float64](c, "$.ratio"); err != nil || v != 0.5 {
+		t.Fatalf("Expected 0.5, got: %v, %v", v, err)
+	}
+	if v, err := Get[bool](c, "$.debug"); err != nil || !v {
+		t.Fatalf("Expected true, got: %v, %v", v, err)
+	}
+	if v, err := Get[string](c, "$.motto"); err != nil || v != "say \"hi\"\n" {
+		t.Fatalf("Expected the decoded string, got: %q, %v", v, err)
+	}
+	if v, err := Get[uint16](c, "$.port"); err != nil || v != 8080 {
+		t.Fatalf("Expected 8080, got: %v, %v", v, err)
+	}
+	if v, err := Get[[]string](c, "$.tags"); err != nil || !reflect.DeepEqual(v, []string{"a", "b"}) {
+		t.Fatalf("Expected [a b], got: %v, %v", v, err)
+	}
+	if v, err := Get[map[string]int](c, "$.limits"); err != nil || !reflect.DeepEqual(v, map[string]int{"cpu": 2, "memory": 512}) {
+		t.Fatalf("Expected the limits, got: %v, %v", v, err)
+	}
+	if v, err := Get[testGetConfig](c, "$.servers[0]"); err != nil || v != (testGetConfig{Name: "one", Port: 1}) {
+		t.Fatalf("Expected the first server, got: %+v, %v", v, err)
+	}
+	if v, err := Get[*testGetConfig](c, "$"); err != nil || *v != (testGetConfig{Name: "dora", Port: 8080}) {
+		t.Fatalf("Expected the config, got: %+v, %v", v, err)
+	}
+
+	if v, err := Get[int](c, "$.name"); !errors.Is(err, ErrTypeMismatch) || v != 0 {
+		t.Fatalf("Expected ErrTypeMismatch and a zero value, got: %v, %v", v, err)
+	}
+	if v, err := Get[string](c, "$.port"); !errors.Is(err, ErrTypeMismatch) || v != "" {
+		t.Fatalf("Expected ErrTypeMismatch for a number as a string, got: %q, %v", v, err)
+	}
+	if v, err := Get[string](c, "$.tags"); !errors.Is(err, ErrTypeMismatch) || v != "" {
+		t.Fatalf("Expected ErrTypeMismatch for an array as a string, got: %q, %v", v, err)
+	}
+	if v, err := Get[bool](c, "$.enabled"); !errors.Is(err, ErrTypeMismatch) || v {
+		t.Fatalf("Expected ErrTypeMismatch for a string as a bool, got: %v, %v", v, err)
+	}
+	if v, err := Get[float64](c, "$.name"); !errors.Is(err, ErrTypeMismatch) || v != 0 {
+		t.Fatalf("Expected ErrTypeMismatch for a string as a float64, got: %v, %v", v, err)
+	}
+	var typeErr *UnmarshalTypeError
+	if _, err := Get[[]int](c, "$.tags"); !errors.As(err, &typeErr) {
+		t.Fatalf("Expected an *UnmarshalTypeError, got: %v", err)
+	}
+	if _, err := Get[string](c, "$.missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+}
+
+func TestGetOr(t *testing.T) {
+	c, err := NewFromString(`{ "port": 8080, "name": "dora", "servers": [] }`)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	if v, err := GetOr(c, "$.port", 80); err != nil || v != 8080 {
+		t.Fatalf("Expected 8080, got: %v, %v", v, err)
+	}
+	if v, err := GetOr(c, "$.timeout", 30); err != nil || v != 30 {
+		t.Fatalf("Expected the default for a missing key, got: %v, %v", v, err)
+	}
+	if v, err := GetOr(c, "$.servers[0].name", "none"); err != nil || v != "none" {
+		t.Fatalf("Expected the default for a missing index, got: %v, %v", v, err)
+	}
+	if v, err := GetOr(c, "$.limits", map[string]int{"cpu": 1}); err != nil || v["cpu"] != 1 {
+		t.Fatalf("Expected the default map, got: %v, %v", v, err)
+	}
+	if _, err := GetOr(c, "$.name", 80); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("Expected ErrTypeMismatch for a value of the wrong type, got: %v", err)
+	}
+	if _, err := GetOr(c, "$.port", "none"); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("Expected ErrTypeMismatch for a number as a string, got: %v", err)
+	}
+	if _, err := GetOr(c, "$.name", false); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("Expected ErrTypeMismatch for a string as a bool, got: %v", err)
+	}
+	if _, err := GetOr(c, "$.servers", 1.5); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("Expected ErrTypeMismatch for an array as a float64, got: %v", err)
+	}
+	if _, err := GetOr(c, "$.servers[*]", "none"); !errors.Is(err, ErrMultipleValues) {
+		t.Fatalf("Expected ErrMultipleValues, got: %v", err)
+	}
+	if _, err := GetOr(c, "$[", 0); !errors.Is(err, ErrQuerySyntax) {
+		t.Fatalf("Expected ErrQuerySyntax, got: %v", err)
+	}
+}
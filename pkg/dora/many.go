@@ -0,0 +1,126 @@
+package dora
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/bradford-hamilton/dora/pkg/ast"
+)
+
+// GetMany resolves many queries at once and returns the value of each, formatted the way GetString
+// formats it, keyed by query. The queries are gathered into a trie of their steps, so the document
+// is walked once for all of them, and queries sharing a prefix share the walk to it, ex:
+// `$.data.users[0].email` and `$.data.users[0].age`. Every query is attempted: those that fail are
+// left out of the results, and their errors are returned joined together, each the same error
+// GetString would return for the query.
+func (c *Client) GetMany(queries ...string) (map[string]string, error) {
+	trie := &queryTrie{}
+	compiled := make([]*Query, len(queries))
+	var errs []error
+	for i, query := range queries {
+		q, err := Compile(query)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		compiled[i] = q
+		if isSingular(q.segments) {
+			trie.insert(q.segments, i)
+		}
+	}
+
+	found := make([]ast.ValueContent, len(queries))
+	trie.resolve(ast.Unwrap(c.tree.RootValue.Content), found)
+
+	results := make(map[string]string, len(queries))
+	for i, q := range compiled {
+		if q == nil {
+			continue
+		}
+		if found[i] == nil {
+			// Resolving a query that failed again on its own gives the same error GetString would
+			if _, err := c.resolveQuery(q); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		results[q.query] = c.resultFromValue(found[i])
+	}
+	return results, errors.Join(errs...)
+}
+
+// queryTrie holds singular queries by their steps. Each node is a step, with the steps that can follow
+// it as children, and the queries that end there.
+type queryTrie struct {
+	keys    map[string]*queryTrie
+	indexes map[int]*queryTrie
+	queries []int // the queries that end here, by their position in the batch
+}
+
+// insert adds the query at position i of the batch, which must be singular.
+func (t *queryTrie) insert(segments []segment, i int) {
+	for _, seg := range segments {
+		sel := seg.selectors[0]
+		if sel.kind == nameSelector {
+			if t.keys == nil {
+				t.keys = make(map[string]*queryTrie)
+			}
+			if t.keys[sel.name] == nil {
+				t.keys[sel.name] = &queryTrie{}
+			}
+			t = t.keys[sel.name]
+			continue
+		}
+		if t.indexes == nil {
+			t.indexes = make(map[int]*queryTrie)
+		}
+		if t.indexes[sel.index] == nil {
+			t.indexes[sel.index] = &queryTrie{}
+		}
+		t = t.indexes[sel.index]
+	}
+	t.queries = append(t.queries, i)
+}
+
+// resolve walks the document beneath node, which the path to t leads to, recording the node each query
+// ends at in found. Each object's members are scanned once for all of the keys that follow t, and a
+// query whose next step doesn't apply to node is left unresolved.
+func (t *queryTrie) resolve(node ast.ValueContent, found []ast.ValueContent) {
+	for _, i := range t.queries {
+		found[i] = node
+	}
+
+	switch n := node.(type) {
+	case ast.Object:
+		if len(t.keys) == 0 {
+			return
+		}
+		// Like a single query, a key that appears more than once selects its first member
+		visited := make(map[*queryTrie]bool, len(t.keys))
+		for _, prop := range n.Children {
+			key := prop.Key.Value
+			if strings.IndexByte(key, '\\') >= 0 {
+				decoded, err := prop.Key.Decoded()
+				if err != nil {
+					continue
+				}
+				key = decoded
+			}
+			child := t.keys[key]
+			if child == nil || visited[child] {
+				continue
+			}
+			visited[child] = true
+			child.resolve(ast.Unwrap(prop.Value), found)
+			if len(visited) == len(t.keys) {
+				return
+			}
+		}
+	case ast.Array:
+		for index, child := range t.indexes {
+			if i := normalizeIndex(index, len(n.Children)); i >= 0 && i < len(n.Children) {
+				child.resolve(ast.Unwrap(n.Children[i].Value), found)
+			}
+		}
+	}
+}
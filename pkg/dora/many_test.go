@@ -0,0 +1,121 @@
+package dora
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestClient_GetMany(t *testing.T) {
+	c, err := NewFromString(TestJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	queries := []string{
+		"$",
+		"$.data.users[0].email",
+		"$.data.users[0].age",
+		"$.data.users[-1].random_items[1].dog_name",
+		"$['data'][\"users\"][0].email",
+		"$.codes[-1]",
+		"$.codes[0]",
+		"$.superNest.inner1.inner2.inner3.inner4[0].inner5.inner6",
+		"$.enabled",
+		"$.data.users[0].allergies",
+		"$.data.users[0].missing",
+		"$.codes[9]",
+		"$.codes[*]",
+		"$.date.year",
+		"$.data.",
+	}
+	results, err := c.GetMany(queries...)
+
+	expected := map[string]string{
+		"$.data.users[0].email":                     "brad@example.com",
+		"$.data.users[0].age":                       "30",
+		"$.data.users[-1].random_items[1].dog_name": "ellie",
+		"$['data'][\"users\"][0].email":             "brad@example.com",
+		"$.codes[-1]":                               "404.567000",
+		"$.codes[0]":                                "200",
+		"$.superNest.inner1.inner2.inner3.inner4[0].inner5.inner6": "neato",
+		"$.enabled":                 "true",
+		"$.data.users[0].allergies": "null",
+	}
+	if results["$"] != TestJSON[1:] {
+		t.Fatalf("Expected $ to be the whole document, got: %s", results["$"])
+	}
+	delete(results, "$")
+	if !reflect.DeepEqual(results, expected) {
+		t.Fatalf("Expected results %v, got: %v", expected, results)
+	}
+
+	// Each failing query gives the same error GetString would
+	for _, query := range queries[10:] {
+		_, expectedErr := c.GetString(query)
+		if !containsError(err, expectedErr) {
+			t.Fatalf("Expected the errors to include %v, got: %v", expectedErr, err)
+		}
+	}
+	for _, target := range []error{ErrKeyNotFound, ErrIndexOutOfRange, ErrMultipleValues, ErrTypeMismatch, ErrQuerySyntax} {
+		if !errors.Is(err, target) {
+			t.Fatalf("Expected the errors to include %v, got: %v", target, err)
+		}
+	}
+
+	// Keys written with escapes in the document, and duplicate keys, resolve the way single queries do
+	c, err = NewFromString(`{ "ab": 1, "x": { "y": 1 }, "x": { "y": 2 }, "arr": [[0, 1], [2, 3]] }`)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+	batch := []string{"$.ab", "$.x.y", "$.arr[1][0]", "$.arr[0][-1]", "$.arr[-1][1]"}
+	results, err = c.GetMany(batch...)
+	if err != nil {
+		t.Fatalf("Failed to get many. Error: %v", err)
+	}
+	for _, query := range batch {
+		single, err := c.GetString(query)
+		if err != nil {
+			t.Fatalf("Failed to get %s. Error: %v", query, err)
+		}
+		if results[query] != single {
+			t.Fatalf("Expected %s to be %s, got: %s", query, single, results[query])
+		}
+	}
+
+	if results, err := c.GetMany(); err != nil || len(results) != 0 {
+		t.Fatalf("Expected no results for no queries, got: %v, %v", results, err)
+	}
+}
+
+// containsError reports whether err, or one of the errors joined into it, has the same message as target.
+func containsError(err, target error) bool {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			if e.Error() == target.Error() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func BenchmarkGetMany(b *testing.B) {
+	c, err := NewFromString(TestJSON)
+	if err != nil {
+		b.Fatalf("\nError creating client: %v\n", err)
+	}
+	var queries []string
+	for _, key := range []string{"first_name", "last_name", "email", "confirmed", "allergies", "age"} {
+		queries = append(queries, fmt.Sprintf("$.data.users[0].%s", key))
+	}
+	queries = append(queries, "$.date", "$.enabled", "$.PI", "$.disabled", "$.codes[0]", "$.codes[-1]")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.GetMany(queries...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
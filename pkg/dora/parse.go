@@ -1,137 +1,174 @@
 package dora
 
 import (
+	"errors"
 	"fmt"
-	"strconv"
 
-	"github.com/bradford-hamilton/dora/pkg/danger"
+	"github.com/bradford-hamilton/dora/pkg/jsonpath"
 )
 
-// The available accessTypes for a dora query
+// segment represents a single "step" in each query. Queries are parsed into a []segment to be used for
+// exploring the JSON. A child segment applies its selectors to each node matched so far, and a descendant
+// segment (`..`) applies them to each node matched so far and every node beneath it.
+type segment struct {
+	descendant bool
+	selectors  []selector
+	pos        int // the byte offset of the segment in the query
+}
+
+// The available kinds of selector, see RFC 9535 section 2.3
 const (
-	ObjectAccess accessType = iota
-	ArrayAccess
+	nameSelector     selectorKind = iota // a key like `.name` or `['name']`
+	wildcardSelector                     // every member or element, `.*` or `[*]`
+	indexSelector                        // an index like `[0]`, or `[-1]` for the last element
+	sliceSelector                        // a slice like `[1:3]` or `[::-1]`
+	filterSelector                       // a filter like `[?(@.age > 21)]`
 )
 
-type accessType int
+type selectorKind int
 
-// queryToken represents a single "step" in each query.
-// Queries are parsed into a []queryTokens to be used for exploring the JSON.
-type queryToken struct {
-	accessType accessType // ObjectAccess or ArrayAccess
-	key        string     // a key like "name"
-	index      int        // an index selection like 0, 1, 2
+// selector picks children of a node. Several selectors in one bracket, ex: `[0, 2]`, form a union and
+// their matches are combined in the order the selectors are written.
+type selector struct {
+	kind   selectorKind
+	name   string     // the key for name selectors, with its escapes decoded
+	index  int        // the index for index selectors
+	slice  *slice     // the bounds for slice selectors
+	filter filterExpr // the expression for filter selectors
 }
 
-// scanQueryTokens scans a users query input into a collection of queryTokens.
-// Dora's query syntax is very straight forward, here is a quick BNF-like representation:
-//    <dora-query>  ::= <querystring>
-//    <querystring> ::= "<query>,*"
-//    <query>       ::= "[<int>]" | "." + <string>
-func scanQueryTokens(query []byte) ([]queryToken, error) {
-	var qts []queryToken
-	queryLen := len(query)
-
-	// Start at 1 to ignore the `$`, which has already been validated at this point.
-	for i := 1; i < queryLen-1; i++ {
-		switch query[i] {
-		case '.':
-			// Step into the key, ex: - If we were at the `.` in `.name` this bumps us to `n`.
-			i++
-
-			// Retrieve the selector and how far to increase `i` (jump).
-			s, jump, _, err := parseObjSelector(query[i:])
-			if err != nil {
-				return []queryToken{}, err
-			}
-
-			// Append our new query token and adjust the jump.
-			qts = append(qts, queryToken{accessType: ObjectAccess, key: danger.BytesToString(s)})
-			i += jump - 1
-		case '[':
-			// Step into the index, ex: - If we were at the `[` in `[123]` this bumps us to `1`
-			i++
-
-			// Retrieve the selector and how far to increase `i` (jump).
-			s, jump, err := parseArraySelector(query[i:])
-			if err != nil {
-				return []queryToken{}, err
-			}
-
-			// The array selector is an int, and we want assert that.
-			index, err := strconv.Atoi(danger.BytesToString(s))
-			if err != nil {
-				return []queryToken{}, err
-			}
-
-			// Append our new query token and adjust the jump
-			qts = append(qts, queryToken{accessType: ArrayAccess, index: index})
-			i += jump
-		default:
-			return []queryToken{}, errSelectorSytax(string(query[i]))
+// isSingular reports whether a query can match at most one node: every segment is a child segment with
+// a single name or index selector.
+func isSingular(segments []segment) bool {
+	for _, seg := range segments {
+		if seg.descendant || len(seg.selectors) != 1 {
+			return false
+		}
+		if kind := seg.selectors[0].kind; kind != nameSelector && kind != indexSelector {
+			return false
 		}
 	}
+	return true
+}
 
-	return qts, nil
+// scanQuery parses a query into segments. Queries are parsed by the jsonpath package, and its syntax tree
+// is turned into the segments dora evaluates.
+func scanQuery(query []byte) ([]segment, error) {
+	parsed, err := parseQuery(string(query))
+	if err != nil {
+		return nil, err
+	}
+	return compileSegments(parsed.Segments), nil
 }
 
-// parseObjSelector consumes the property key, sets the `jump` index to right after it, and returns the sliced chunk.
-func parseObjSelector(queryChunk []byte) ([]byte, int, bool, error) {
-	var jump int
-	var isIndex bool
-	queryLen := len(queryChunk)
+// parseQuery parses a query into its syntax tree, returning syntax errors as a QueryError.
+func parseQuery(query string) (*jsonpath.Query, error) {
+	if len(query) == 0 || query[0] != '$' {
+		return nil, &QueryError{Query: query, Err: ErrNoDollarSignRoot}
+	}
 
-	if isPropertyKey(queryChunk[jump]) {
-		// Consume the property key name and increment the jump to point to after the property key
-		for isPropertyKey(queryChunk[jump]) && jump < queryLen-1 {
-			jump++
+	parsed, err := jsonpath.Parse(query)
+	if err != nil {
+		var syntaxErr *jsonpath.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			return nil, err
 		}
+		return nil, &QueryError{Query: query, Pos: syntaxErr.Pos, Err: fmt.Errorf("%w: %s", ErrQuerySyntax, syntaxErr.Msg)}
+	}
+	return parsed, nil
+}
 
-		// After consuming the property key above, we should be onto the next selector.
-		// This has to either be another object or an array
-		if queryChunk[jump] == '.' || queryChunk[jump] == '[' {
-			return queryChunk[0:jump], jump, isIndex, nil
-		} else if jump == queryLen-1 {
-			// we are on the last byte
-			return queryChunk[0 : jump+1], jump, isIndex, nil
+// compileSegments turns parsed segments into the segments dora evaluates.
+func compileSegments(parsed []jsonpath.Segment) []segment {
+	var segments []segment
+	for _, seg := range parsed {
+		compiled := segment{descendant: seg.Descendant, pos: seg.Pos}
+		for _, sel := range seg.Selectors {
+			compiled.selectors = append(compiled.selectors, compileSelector(sel))
 		}
-
-		return nil, 0, isIndex, errSelectorSytax(string(queryChunk[jump]))
+		segments = append(segments, compiled)
 	}
-
-	return nil, 0, isIndex, fmt.Errorf(
-		"Error parsing object selector within query. Expected string, but started with %s",
-		string(queryChunk[jump]),
-	)
+	return segments
 }
 
-// parseArraySelector consumes the array index request, sets the `jump` index to right after it, and returns the sliced chunk.
-func parseArraySelector(queryChunk []byte) ([]byte, int, error) {
-	var jump int
-	queryLen := len(queryChunk)
-
-	if isNumber(queryChunk[jump]) {
-		// Consume the index and return it along with the jump
-		for isNumber(queryChunk[jump]) && jump < queryLen-1 {
-			jump++
+func compileSelector(sel jsonpath.Selector) selector {
+	switch s := sel.(type) {
+	case jsonpath.NameSelector:
+		return selector{kind: nameSelector, name: s.Name}
+	case jsonpath.WildcardSelector:
+		return selector{kind: wildcardSelector}
+	case jsonpath.IndexSelector:
+		return selector{kind: indexSelector, index: s.Index}
+	case jsonpath.SliceSelector:
+		sl := slice{step: 1}
+		if s.Start != nil {
+			sl.start, sl.hasStart = *s.Start, true
+		}
+		if s.End != nil {
+			sl.end, sl.hasEnd = *s.End, true
+		}
+		if s.Step != nil {
+			sl.step = *s.Step
 		}
-		return queryChunk[0:jump], jump, nil
+		return selector{kind: sliceSelector, slice: &sl}
+	case jsonpath.FilterSelector:
+		return selector{kind: filterSelector, filter: compileFilter(s.Filter)}
+	default:
+		panic(fmt.Sprintf("dora: unexpected selector %T", sel))
 	}
-
-	return nil, 0, fmt.Errorf(
-		"Error parsing array selector within query. Expected an int, but started with %s",
-		string(queryChunk[jump]),
-	)
 }
 
-func isPropertyKey(char byte) bool {
-	return isLetter(char) || isNumber(char)
+// slice is a parsed `[start:end:step]` selection. Start and end are optional, and their defaults depend
+// on the direction of the step.
+type slice struct {
+	start, end       int
+	hasStart, hasEnd bool
+	step             int
 }
 
-func isLetter(char byte) bool {
-	return 'a' <= char && char <= 'z' || 'A' <= char && char <= 'Z' || char == '_'
+// indices returns the indexes the slice selects from an array of the given length, in the order it
+// selects them. This follows the slice semantics of RFC 9535, so a step of 0 selects nothing and
+// out of range bounds are clamped to the array.
+func (sl *slice) indices(length int) []int {
+	if sl.step == 0 {
+		return nil
+	}
+
+	var out []int
+	if sl.step > 0 {
+		start, end := 0, length
+		if sl.hasStart {
+			start = normalizeIndex(sl.start, length)
+		}
+		if sl.hasEnd {
+			end = normalizeIndex(sl.end, length)
+		}
+		lower, upper := min(max(start, 0), length), min(max(end, 0), length)
+		for i := lower; i < upper; i += sl.step {
+			out = append(out, i)
+		}
+		return out
+	}
+
+	start, end := length-1, -length-1
+	if sl.hasStart {
+		start = normalizeIndex(sl.start, length)
+	}
+	if sl.hasEnd {
+		end = normalizeIndex(sl.end, length)
+	}
+	upper, lower := min(max(start, -1), length-1), min(max(end, -1), length-1)
+	for i := upper; lower < i; i += sl.step {
+		out = append(out, i)
+	}
+	return out
 }
 
-func isNumber(char byte) bool {
-	return '0' <= char && char <= '9'
+// normalizeIndex turns a negative index, which counts back from the end of the array, into an
+// index from the start of the array.
+func normalizeIndex(index, length int) int {
+	if index < 0 {
+		return length + index
+	}
+	return index
 }
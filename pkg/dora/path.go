@@ -0,0 +1,218 @@
+package dora
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/bradford-hamilton/dora/pkg/ast"
+	"github.com/bradford-hamilton/dora/pkg/jsonpath"
+)
+
+// The available targets a source position can fall on
+const (
+	// TargetValue means the position is on a literal value (string, number, boolean, or null).
+	TargetValue Target = iota
+	// TargetKey means the position is on an object key.
+	TargetKey
+	// TargetPunctuation means the position is on structure rather than a key or a literal: braces,
+	// brackets, colons, commas, or the whitespace and comments between them.
+	TargetPunctuation
+)
+
+// Target describes which part of a node a source position falls on.
+type Target int
+
+// String returns a readable name for the Target.
+func (t Target) String() string {
+	switch t {
+	case TargetValue:
+		return "value"
+	case TargetKey:
+		return "key"
+	case TargetPunctuation:
+		return "punctuation"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrPositionOutOfRange is returned when a source position doesn't fall on the root value of the document.
+var ErrPositionOutOfRange = errors.New("position is outside of the JSON document's root value")
+
+// PathInfo describes the innermost node found at a position in the source document.
+type PathInfo struct {
+	Path   string   // The query path of the node, ex: `$.data.users[0].email`
+	Target Target   // Which part of the node the position falls on
+	Span   ast.Span // The source range of the node's value
+}
+
+// PathAt returns the path of the innermost node at the given byte offset in the source
+// document, along with whether the offset is on the key, the value, or the punctuation
+// around them. This is the reverse of `Locate`.
+func (c *Client) PathAt(offset int) (PathInfo, error) {
+	root := ast.Unwrap(c.tree.RootValue.Content)
+	if !spanContains(ast.SpanOf(root), offset) {
+		return PathInfo{}, ErrPositionOutOfRange
+	}
+	return pathAt(root, offset, []byte{'$'}), nil
+}
+
+// PathAtPosition is the same as `PathAt`, except it takes a line and column (both starting at 1).
+func (c *Client) PathAtPosition(line, column int) (PathInfo, error) {
+	offset, ok := c.offsetOf(line, column)
+	if !ok {
+		return PathInfo{}, ErrPositionOutOfRange
+	}
+	return c.PathAt(offset)
+}
+
+// pathAt walks down from node towards the innermost node containing offset, extending path as it goes.
+func pathAt(node ast.ValueContent, offset int, path []byte) PathInfo {
+	switch n := node.(type) {
+	case ast.Object:
+		for _, prop := range n.Children {
+			if !spanContains(ast.SpanOf(prop), offset) {
+				continue
+			}
+			key, err := prop.Key.Decoded()
+			if err != nil {
+				key = prop.Key.Value
+			}
+			propPath := appendKeyToPath(path, key)
+			if spanContains(ast.SpanOf(prop.Key), offset) {
+				return PathInfo{Path: string(propPath), Target: TargetKey, Span: ast.SpanOf(prop.Value)}
+			}
+			value := ast.Unwrap(prop.Value)
+			if spanContains(ast.SpanOf(value), offset) {
+				return pathAt(value, offset, propPath)
+			}
+			// Between the key and the value, ex: on the colon
+			return PathInfo{Path: string(propPath), Target: TargetPunctuation, Span: ast.SpanOf(value)}
+		}
+		return PathInfo{Path: string(path), Target: TargetPunctuation, Span: ast.SpanOf(n)}
+	case ast.Array:
+		for i, item := range n.Children {
+			value := ast.Unwrap(item.Value)
+			if spanContains(ast.SpanOf(value), offset) {
+				return pathAt(value, offset, appendIndexToPath(path, i))
+			}
+		}
+		return PathInfo{Path: string(path), Target: TargetPunctuation, Span: ast.SpanOf(n)}
+	default:
+		return PathInfo{Path: string(path), Target: TargetValue, Span: ast.SpanOf(n)}
+	}
+}
+
+// offsetOf converts a line and column into a byte offset in the client's input.
+func (c *Client) offsetOf(line, column int) (int, bool) {
+	if line < 1 || column < 1 {
+		return 0, false
+	}
+	lineStart := 0
+	for l := 1; l < line; l++ {
+		next := bytes.IndexByte(c.input[lineStart:], '\n')
+		if next < 0 {
+			return 0, false
+		}
+		lineStart += next + 1
+	}
+	lineEnd := len(c.input)
+	if next := bytes.IndexByte(c.input[lineStart:], '\n'); next >= 0 {
+		lineEnd = lineStart + next
+	}
+	offset := lineStart + column - 1
+	if offset > lineEnd {
+		return 0, false
+	}
+	return offset, true
+}
+
+// spanContains reports whether offset is within the span. Spans are half open, so the
+// end offset (directly after the node) isn't part of it.
+func spanContains(span ast.Span, offset int) bool {
+	return span.Start.IsValid() && span.Start.Offset <= offset && offset < span.End.Offset
+}
+
+// appendKeyToPath appends an object selector for key to a query path. Keys that can be written after a `.`
+// use dot notation, and any other key is quoted in brackets, ex: `['first-name']`.
+func appendKeyToPath(path []byte, key string) []byte {
+	return jsonpath.AppendName(path, key)
+}
+
+// appendQuotedKeyToPath appends key to a query path quoted in brackets, escaped the way RFC 9535
+// escapes normalized paths.
+func appendQuotedKeyToPath(path []byte, key string) []byte {
+	path = append(path, '[')
+	path = jsonpath.AppendQuoted(path, key)
+	return append(path, ']')
+}
+
+// appendIndexToPath appends an array selector for index to a query path.
+func appendIndexToPath(path []byte, index int) []byte {
+	path = append(path, '[')
+	path = strconv.AppendInt(path, int64(index), 10)
+	return append(path, ']')
+}
+
+// Paths returns the normalized path of every value a query matches, in the same order as GetAll. A
+// normalized path spells out each step with brackets, ex: `$['data']['users'][0]['email']`, so every
+// value in the document has exactly one (see RFC 9535 section 2.7).
+func (c *Client) Paths(query string) ([]string, error) {
+	q, err := Compile(query)
+	if err != nil {
+		return nil, err
+	}
+	root := ast.Unwrap(c.tree.RootValue.Content)
+	nodes := c.resolveAll(q.segments)
+	paths := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		paths = append(paths, string(normalizedPath(root, node, []byte{'$'})))
+	}
+	return paths, nil
+}
+
+// normalizedPath walks down from node to target, which must be beneath it, extending path as it goes.
+// Nodes are found by their source position, as every node in a parsed document has its own span.
+// Children are in source order, so each step is a binary search rather than a scan, which keeps
+// finding the path of every value in a wide array from being quadratic.
+func normalizedPath(node, target ast.ValueContent, path []byte) []byte {
+	span := ast.SpanOf(target)
+	offset := span.Start.Offset
+	for ast.SpanOf(node) != span {
+		var next ast.ValueContent
+		switch n := node.(type) {
+		case ast.Object:
+			i := sort.Search(len(n.Children), func(i int) bool {
+				return ast.SpanOf(ast.Unwrap(n.Children[i].Value)).End.Offset > offset
+			})
+			if i < len(n.Children) {
+				prop := n.Children[i]
+				if value := ast.Unwrap(prop.Value); spanContains(ast.SpanOf(value), offset) {
+					key, err := prop.Key.Decoded()
+					if err != nil {
+						key = prop.Key.Value
+					}
+					path = appendQuotedKeyToPath(path, key)
+					next = value
+				}
+			}
+		case ast.Array:
+			i := sort.Search(len(n.Children), func(i int) bool {
+				return ast.SpanOf(ast.Unwrap(n.Children[i].Value)).End.Offset > offset
+			})
+			if i < len(n.Children) {
+				if value := ast.Unwrap(n.Children[i].Value); spanContains(ast.SpanOf(value), offset) {
+					path = appendIndexToPath(path, i)
+					next = value
+				}
+			}
+		}
+		if next == nil {
+			return path
+		}
+		node = next
+	}
+	return path
+}
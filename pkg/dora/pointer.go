@@ -0,0 +1,212 @@
+package dora
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bradford-hamilton/dora/pkg/ast"
+	"github.com/bradford-hamilton/dora/pkg/jsonpath"
+)
+
+// ErrInvalidPointer is used for telling the user a JSON Pointer isn't written correctly
+var ErrInvalidPointer = errors.New(
+	"Incorrect syntax. JSON Pointers must be empty or start with `/`, and `~` must be followed by `0` or `1`. Ex: `/data/users/0/email`",
+)
+
+// GetPointer resolves a JSON Pointer (RFC 6901), ex: `/data/users/0/email`, and returns the value it
+// points at formatted the way GetString formats it. Each reference token selects a key of an object,
+// or an index of an array when the value is an array, with `~1` standing for `/` and `~0` for `~`.
+func (c *Client) GetPointer(pointer string) (string, error) {
+	node, _, err := c.resolvePointer(pointer)
+	if err != nil {
+		return "", err
+	}
+	return c.resultFromValue(node), nil
+}
+
+// LocatePointer is the same as Locate, except it takes a JSON Pointer rather than a query.
+func (c *Client) LocatePointer(pointer string) (ast.Span, error) {
+	node, _, err := c.resolvePointer(pointer)
+	if err != nil {
+		return ast.Span{}, err
+	}
+	return ast.SpanOf(node), nil
+}
+
+// PointerToPath converts a JSON Pointer into a dora query the way the package's PointerToPath does,
+// except each token is resolved against the document, so a token made of digits becomes a key when
+// it's applied to an object, ex: `/0/1/0` becomes `$['0']['1'][0]` for `{"0": {"1": ["x"]}}`. The
+// pointer must point at a value in the document.
+func (c *Client) PointerToPath(pointer string) (string, error) {
+	_, path, err := c.resolvePointer(pointer)
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// resolvePointer walks the tree following a JSON Pointer, returning the value it points at and its path
+// as a query. Unlike a query, a pointer doesn't say whether a token is a key or an index, so that's
+// decided by the value the token is applied to.
+func (c *Client) resolvePointer(pointer string) (ast.ValueContent, string, error) {
+	tokens, err := splitPointer(pointer)
+	if err != nil {
+		return nil, "", err
+	}
+	current := ast.Unwrap(c.tree.RootValue.Content)
+	path := []byte{'$'}
+	fail := func(tok pointerToken, err error) error {
+		return &QueryError{Query: pointer, Pos: tok.pos, Path: string(path), Err: err}
+	}
+
+	for _, tok := range tokens {
+		switch node := current.(type) {
+		case ast.Object:
+			var found bool
+			for _, prop := range node.Children {
+				if keyMatches(prop.Key, tok.name) {
+					current = ast.Unwrap(prop.Value)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, "", fail(tok, fmt.Errorf("%w. Key: %s", ErrKeyNotFound, tok.name))
+			}
+			path = appendKeyToPath(path, tok.name)
+		case ast.Array:
+			// Tokens that aren't indexes, including `-` (the position after the last element), never
+			// point at an element
+			index, ok := pointerIndex(tok.name)
+			if !ok || index >= len(node.Children) {
+				return nil, "", fail(tok, fmt.Errorf("%w: index %s on an array of length %d", ErrIndexOutOfRange, tok.name, len(node.Children)))
+			}
+			current = ast.Unwrap(node.Children[index].Value)
+			path = appendIndexToPath(path, index)
+		default:
+			return nil, "", fail(tok, fmt.Errorf("%w: your pointer asked for %q of a %s", ErrTypeMismatch, tok.name, kindOf(node)))
+		}
+	}
+
+	return current, string(path), nil
+}
+
+// pointerToken is a reference token of a JSON Pointer, with its escapes decoded, and the byte offset in
+// the pointer of the `/` before it.
+type pointerToken struct {
+	name string
+	pos  int
+}
+
+// splitPointer splits a JSON Pointer into its reference tokens.
+func splitPointer(pointer string) ([]pointerToken, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, &QueryError{Query: pointer, Err: ErrInvalidPointer}
+	}
+
+	var tokens []pointerToken
+	pos := 0
+	for _, tok := range strings.Split(pointer[1:], "/") {
+		if !strings.Contains(tok, "~") {
+			tokens = append(tokens, pointerToken{name: tok, pos: pos})
+			pos += len(tok) + 1
+			continue
+		}
+		var b strings.Builder
+		for j := 0; j < len(tok); j++ {
+			if tok[j] != '~' {
+				b.WriteByte(tok[j])
+				continue
+			}
+			if j+1 == len(tok) || (tok[j+1] != '0' && tok[j+1] != '1') {
+				return nil, &QueryError{Query: pointer, Pos: pos + 1 + j, Err: ErrInvalidPointer}
+			}
+			j++
+			if tok[j] == '0' {
+				b.WriteByte('~')
+			} else {
+				b.WriteByte('/')
+			}
+		}
+		tokens = append(tokens, pointerToken{name: b.String(), pos: pos})
+		pos += len(tok) + 1
+	}
+	return tokens, nil
+}
+
+// pointerIndex parses a reference token as an array index. RFC 6901 only allows digits without
+// leading zeros, so `-1` or `01` aren't indexes.
+func pointerIndex(tok string) (int, bool) {
+	if tok == "" || (tok[0] == '0' && len(tok) > 1) {
+		return 0, false
+	}
+	for i := 0; i < len(tok); i++ {
+		if !isNumber(tok[i]) {
+			return 0, false
+		}
+	}
+	index, err := strconv.Atoi(tok)
+	if err != nil || index > jsonpath.MaxIndex {
+		return 0, false
+	}
+	return index, true
+}
+
+// PointerToPath converts a JSON Pointer into a dora query, ex: `/data/users/0/email` becomes
+// `$.data.users[0].email`. A pointer doesn't say whether a token is a key or an index, so tokens made
+// of digits become indexes, which is what they are in most documents. Use Client.PointerToPath to
+// convert a pointer exactly, against the document it points into.
+func PointerToPath(pointer string) (string, error) {
+	tokens, err := splitPointer(pointer)
+	if err != nil {
+		return "", err
+	}
+
+	path := []byte{'$'}
+	for _, tok := range tokens {
+		if index, ok := pointerIndex(tok.name); ok {
+			path = appendIndexToPath(path, index)
+			continue
+		}
+		path = appendKeyToPath(path, tok.name)
+	}
+	return string(path), nil
+}
+
+// PathToPointer converts a dora query into a JSON Pointer, ex: `$.data.users[0].email` becomes
+// `/data/users/0/email`. Only queries that select a single value by keys and non-negative indexes
+// can be written as a pointer.
+func PathToPointer(path string) (string, error) {
+	segments, err := scanQuery([]byte(path))
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for i, seg := range segments {
+		if !isSingular(segments[i : i+1]) {
+			return "", &QueryError{Query: path, Pos: seg.pos, Err: ErrMultipleValues}
+		}
+		sel := seg.selectors[0]
+		b.WriteByte('/')
+		if sel.kind == indexSelector {
+			if sel.index < 0 {
+				err := fmt.Errorf("%w: JSON Pointers can't count back from the end of an array. Index: %d", ErrIndexOutOfRange, sel.index)
+				return "", &QueryError{Query: path, Pos: seg.pos, Err: err}
+			}
+			b.WriteString(strconv.Itoa(sel.index))
+			continue
+		}
+		b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(sel.name))
+	}
+	return b.String(), nil
+}
+
+func isNumber(char byte) bool {
+	return '0' <= char && char <= '9'
+}
@@ -0,0 +1,176 @@
+package dora
+
+import (
+	"errors"
+	"testing"
+)
+
+// testPointerJSON is the example document from RFC 6901 section 5.
+const testPointerJSON = `{
+	"foo": ["bar", "baz"],
+	"": 0,
+	"a/b": 1,
+	"c%d": 2,
+	"e^f": 3,
+	"g|h": 4,
+	"i\\j": 5,
+	"k\"l": 6,
+	" ": 7,
+	"m~n": 8,
+	"0": { "1": ["x"] }
+}`
+
+func TestClient_GetPointer(t *testing.T) {
+	c, err := NewFromString(testPointerJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	tests := [...]struct {
+		pointer  string
+		expected string
+	}{
+		{pointer: "/foo", expected: `["bar", "baz"]`},
+		{pointer: "/foo/0", expected: "bar"},
+		{pointer: "/", expected: "0"},
+		{pointer: "/a~1b", expected: "1"},
+		{pointer: "/c%d", expected: "2"},
+		{pointer: "/e^f", expected: "3"},
+		{pointer: "/g|h", expected: "4"},
+		{pointer: `/i\j`, expected: "5"},
+		{pointer: `/k"l`, expected: "6"},
+		{pointer: "/ ", expected: "7"},
+		{pointer: "/m~0n", expected: "8"},
+		{pointer: "/0/1/0", expected: "x"},
+	}
+
+	for _, tt := range tests {
+		result, err := c.GetPointer(tt.pointer)
+		if err != nil {
+			t.Fatalf("Failed to resolve pointer %q. Error: %v", tt.pointer, err)
+		}
+		if result != tt.expected {
+			t.Fatalf("Expected %q to point at %s, got: %s", tt.pointer, tt.expected, result)
+		}
+	}
+
+	whole, err := c.GetPointer("")
+	if err != nil || whole != testPointerJSON {
+		t.Fatalf("Expected the empty pointer to point at the whole document, got: %s, %v", whole, err)
+	}
+
+	span, err := c.LocatePointer("/foo/1")
+	if err != nil {
+		t.Fatalf("Failed to locate pointer. Error: %v", err)
+	}
+	if got := testPointerJSON[span.Start.Offset:span.End.Offset]; got != `"baz"` {
+		t.Fatalf(`Expected to locate "baz", got: %s`, got)
+	}
+
+	errs := [...]struct {
+		pointer  string
+		expected error
+	}{
+		{pointer: "foo", expected: ErrInvalidPointer},
+		{pointer: "/m~2n", expected: ErrInvalidPointer},
+		{pointer: "/m~", expected: ErrInvalidPointer},
+		{pointer: "/foo/2", expected: ErrIndexOutOfRange},
+		{pointer: "/foo/-", expected: ErrIndexOutOfRange},
+		{pointer: "/foo/01", expected: ErrIndexOutOfRange},
+		{pointer: "/foo/bar", expected: ErrIndexOutOfRange},
+		{pointer: "/missing"},
+		{pointer: "/foo/0/bar"},
+	}
+	for _, tt := range errs {
+		_, err := c.GetPointer(tt.pointer)
+		if err == nil {
+			t.Fatalf("Expected an error resolving %q", tt.pointer)
+		}
+		if tt.expected != nil && !errors.Is(err, tt.expected) {
+			t.Fatalf("Expected %q to fail with %v, got: %v", tt.pointer, tt.expected, err)
+		}
+	}
+}
+
+func TestClient_PointerToPath(t *testing.T) {
+	c, err := NewFromString(testPointerJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	tests := [...]struct {
+		pointer string
+		path    string
+	}{
+		{pointer: "", path: "$"},
+		{pointer: "/foo/1", path: "$.foo[1]"},
+		{pointer: "/0/1/0", path: "$['0']['1'][0]"},
+		{pointer: "/a~1b", path: "$['a/b']"},
+	}
+
+	for _, tt := range tests {
+		path, err := c.PointerToPath(tt.pointer)
+		if err != nil {
+			t.Fatalf("Failed to convert pointer %q. Error: %v", tt.pointer, err)
+		}
+		if path != tt.path {
+			t.Fatalf("Expected pointer %q to convert to %s, got: %s", tt.pointer, tt.path, path)
+		}
+		// The path selects the same value the pointer does
+		byPath, err := c.GetString(path)
+		if err != nil {
+			t.Fatalf("Failed to resolve path %s. Error: %v", path, err)
+		}
+		if byPointer, _ := c.GetPointer(tt.pointer); byPath != byPointer {
+			t.Fatalf("Expected %s to select what %q points at, got: %s", path, tt.pointer, byPath)
+		}
+	}
+
+	if _, err := c.PointerToPath("/0/2"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("Expected ErrKeyNotFound converting a pointer the document doesn't have, got: %v", err)
+	}
+}
+
+func TestPointerConversion(t *testing.T) {
+	tests := [...]struct {
+		pointer string
+		path    string
+	}{
+		{pointer: "", path: "$"},
+		{pointer: "/data/users/0/email", path: "$.data.users[0].email"},
+		{pointer: "/a~1b/m~0n", path: "$['a/b']['m~n']"},
+		{pointer: "/", path: "$['']"},
+		{pointer: "/first-name/10", path: "$['first-name'][10]"},
+		{pointer: "/it's", path: `$['it\'s']`},
+	}
+
+	for _, tt := range tests {
+		path, err := PointerToPath(tt.pointer)
+		if err != nil {
+			t.Fatalf("Failed to convert pointer %q. Error: %v", tt.pointer, err)
+		}
+		if path != tt.path {
+			t.Fatalf("Expected pointer %q to convert to %s, got: %s", tt.pointer, tt.path, path)
+		}
+
+		pointer, err := PathToPointer(tt.path)
+		if err != nil {
+			t.Fatalf("Failed to convert path %s. Error: %v", tt.path, err)
+		}
+		if pointer != tt.pointer {
+			t.Fatalf("Expected path %s to convert to %q, got: %q", tt.path, tt.pointer, pointer)
+		}
+	}
+
+	if path, err := PointerToPath("/01/-"); err != nil || path != "$['01']['-']" {
+		t.Fatalf("Expected tokens that aren't indexes to convert to keys, got: %s, %v", path, err)
+	}
+	if _, err := PointerToPath("data"); !errors.Is(err, ErrInvalidPointer) {
+		t.Fatalf("Expected ErrInvalidPointer, got: %v", err)
+	}
+	for _, path := range []string{"$.users[*]", "$.codes[-1]", "$..email", "users"} {
+		if _, err := PathToPointer(path); err == nil {
+			t.Fatalf("Expected an error converting %s to a pointer", path)
+		}
+	}
+}
@@ -3,9 +3,9 @@ package dora
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/bradford-hamilton/dora/pkg/ast"
-	"github.com/bradford-hamilton/dora/pkg/danger"
 )
 
 var (
@@ -23,218 +23,277 @@ var (
 		"Incorrect syntax. Your root JSON type is an array. Therefore, path queries must" +
 			"begin by selecting an item by index on the root array. Ex: `$[0]` or `$[1]`",
 	)
+	// ErrQuerySyntax is used for telling the user their query isn't written correctly
+	ErrQuerySyntax = errors.New("Incorrect query syntax")
+	// ErrKeyNotFound is used for telling the user their query asked for a key the object doesn't have
+	ErrKeyNotFound = errors.New("Sorry, could not find a key with that value")
+	// ErrIndexOutOfRange is used for telling the user their query asked for an array index the array doesn't have
+	ErrIndexOutOfRange = errors.New("Array index out of range")
+	// ErrTypeMismatch is used for telling the user their query asked for a key of an array, an index of an object,
+	// or a child of a string, number, boolean, or null, or that a value is a different kind than they asked for
+	ErrTypeMismatch = errors.New("Query step doesn't match the type of the value")
+	// ErrMultipleValues is used for telling the user their query can match more than one value, so it must be run with GetAll
+	ErrMultipleValues = errors.New(
+		"Your query uses a wildcard, descendant, slice, filter, or union selector and can match more than one value. Use GetAll to retrieve every match",
+	)
+	// ErrNotProjection is used for telling the user a query passed to Project doesn't end by picking keys from an object
+	ErrNotProjection = errors.New(
+		"Incorrect syntax. Projections must end with a bracket of keys to pick from an object. Ex: `$.users[0]['name', 'email']`",
+	)
 )
 
-// prepAndExecQuery prepares and executes a passed in query
-func (c *Client) prepAndExecQuery(query string) error {
-	if err := c.prepareQuery(query, c.tree.Type); err != nil {
-		return err
-	}
-	if err := c.executeQuery(); err != nil {
-		return err
-	}
-	return nil
+// QueryError is the error returned when a query or JSON Pointer can't be parsed or can't be resolved,
+// or its value can't be returned as the type asked for. Err is one of ErrQuerySyntax, ErrKeyNotFound,
+// ErrIndexOutOfRange, ErrTypeMismatch, or ErrMultipleValues, possibly wrapped with more detail, so
+// failures can be told apart with errors.Is. ErrNoDollarSignRoot, ErrInvalidPointer, and ErrNotProjection
+// also match ErrQuerySyntax, and ErrWrongObjectRootSelector and ErrWrongArrayRootSelector match ErrTypeMismatch.
+type QueryError struct {
+	Query string // The query or pointer that failed
+	Pos   int    // The byte offset in the query of the step that failed
+	Path  string // The path resolved before the failing step, ex: `$.data.users[0]`. Empty for syntax errors
+	Err   error  // What went wrong
 }
 
-// prepareQuery validates the query root, sets the query on the client struct, and parses the query.
-func (c *Client) prepareQuery(query string, rootNodeType ast.RootNodeType) error {
-	if err := validateQueryRoot(query, c.tree.Type); err != nil {
-		return err
-	}
-	c.setQuery(danger.StringToBytes(query))
-	if err := c.parseQuery(); err != nil {
-		return err
+func (e *QueryError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("Error parsing query at %d. %v", e.Pos, e.Err)
 	}
-	return nil
+	return fmt.Sprintf("Error running query %s at %d, after %s. %v", e.Query, e.Pos, e.Path, e.Err)
 }
 
-func (c *Client) setQuery(query []byte) {
-	c.query = query
+// Unwrap returns the underlying error.
+func (e *QueryError) Unwrap() error {
+	return e.Err
 }
 
-// parseQuery is pretty straight forward. Scan the query into tokens, set the the tokns
-// to the `parsedQuery` field on the client.
-func (c *Client) parseQuery() error {
-	tokens, err := scanQueryTokens(c.query)
-	if err != nil {
-		return err
+// Is lets the errors dora returned before the broader ones existed match them too.
+func (e *QueryError) Is(target error) bool {
+	switch target {
+	case ErrQuerySyntax:
+		return errors.Is(e.Err, ErrNoDollarSignRoot) || errors.Is(e.Err, ErrInvalidPointer) || errors.Is(e.Err, ErrNotProjection)
+	case ErrTypeMismatch:
+		return errors.Is(e.Err, ErrWrongObjectRootSelector) || errors.Is(e.Err, ErrWrongArrayRootSelector)
+	default:
+		return false
 	}
-	c.parsedQuery = tokens
-	return nil
 }
 
-// Get takes a dora query, prepares and validates it, executes the query, and returns the result or an error.
+// valueError returns the QueryError for a query that resolved, but to a value that can't be returned
+// the way it was asked for, ex: a string asked for as a number. Every step resolved, so the error is at
+// the end of the query.
+func valueError(query string, err error) error {
+	return &QueryError{Query: query, Pos: len(query), Path: query, Err: err}
+}
+
+// get takes a dora query, compiles it, resolves it, and returns the result or an error.
 func (c *Client) get(query string) (string, error) {
-	if err := c.prepAndExecQuery(query); err != nil {
+	node, err := c.resolve(query)
+	if err != nil {
 		return "", err
 	}
-	return c.result, nil
-}
-
-// executeQuery is called after the JSON and the query are parsed into their respective
-// tokens. We then iterate over the query tokens, and traverse our tree attempting to
-// find the result the user is looking for.
-func (c *Client) executeQuery() error {
-	rootVal := *c.tree.RootValue
-	obj, _ := rootVal.Content.(ast.Object)
-	arr, ok := rootVal.Content.(ast.Array)
-	currentType := ast.ObjectType
-	if ok {
-		currentType = ast.ArrayType
+	return c.resultFromValue(node), nil
+}
+
+// resolve compiles a query and resolves the single node it points at.
+func (c *Client) resolve(query string) (ast.ValueContent, error) {
+	q, err := Compile(query)
+	if err != nil {
+		return nil, err
+	}
+	return c.resolveQuery(q)
+}
+
+// resolveQuery iterates over the query segments and traverses our tree attempting to find
+// the node the user is looking for. The returned node is an ast.Object, ast.Array, or ast.Literal.
+func (c *Client) resolveQuery(q *Query) (ast.ValueContent, error) {
+	current := ast.Unwrap(c.tree.RootValue.Content)
+	path := []byte{'$'}
+	fail := func(seg segment, err error) error {
+		return &QueryError{Query: q.query, Pos: seg.pos, Path: string(path), Err: err}
 	}
-	parsedQueryLen := len(c.parsedQuery)
 
-	for i := 0; i < parsedQueryLen; i++ {
-		// If i == parsedQueryLen-1, we are on the final iteration
-		if i == parsedQueryLen-1 {
-			c.setFinalValue(currentType, i, obj, arr)
+	for i, seg := range q.segments {
+		// A step that can match more than one value fails where it's reached, so the error's path is
+		// the value the step was applied to
+		if !isSingular(q.segments[i : i+1]) {
+			return nil, fail(seg, ErrMultipleValues)
 		}
+		next, nextPath, err := childOf(current, seg.selectors[0], path)
+		if err != nil {
+			return nil, fail(seg, err)
+		}
+		current, path = next, nextPath
+	}
 
-		// If the query token we're on is asking for an object
-		if c.parsedQuery[i].accessType == ObjectAccess {
-			if currentType != ast.ObjectType {
-				return errors.New("incorrect syntax, your query asked for an object but found array")
-			}
-			var found bool
-
-			for _, v := range obj.Children {
-				if v.Key.Value == c.parsedQuery[i].key {
-					found = true
-					val := v.Value
-					if v2, ok := val.(ast.Value); ok {
-						// unwrap the Value
-						val = v2.Content
-					}
-					o, astObj := val.(ast.Object)
-					if astObj {
-						obj = o
-						currentType = ast.ObjectType
-						break
-					}
-					a, astArr := val.(ast.Array)
-					if astArr {
-						arr = a
-						currentType = ast.ArrayType
-						break
-					}
-				}
+	return current, nil
+}
+
+// childOf applies a name or index selector to node, returning the child it selects and path extended
+// to it. Path is the path of node.
+func childOf(node ast.ValueContent, sel selector, path []byte) (ast.ValueContent, []byte, error) {
+	switch n := node.(type) {
+	case ast.Object:
+		// If the selector we're on is asking for an object
+		if sel.kind != nameSelector {
+			if len(path) == 1 {
+				return nil, nil, ErrWrongObjectRootSelector
 			}
-			if !found {
-				return fmt.Errorf("Sorry, could not find a key with that value. Key: %s", c.parsedQuery[i].key)
+			return nil, nil, fmt.Errorf("%w: your query asked for index %d but found an object", ErrTypeMismatch, sel.index)
+		}
+		for _, prop := range n.Children {
+			if keyMatches(prop.Key, sel.name) {
+				return ast.Unwrap(prop.Value), appendKeyToPath(path, sel.name), nil
 			}
-		} else { // If the query token we're on is asking for an array
-			if currentType != ast.ArrayType {
-				return errors.New("incorrect syntax, your query asked for an array but found object")
+		}
+		return nil, nil, fmt.Errorf("%w. Key: %s", ErrKeyNotFound, sel.name)
+	case ast.Array:
+		// If the selector we're on is asking for an array
+		if sel.kind != indexSelector {
+			if len(path) == 1 {
+				return nil, nil, ErrWrongArrayRootSelector
 			}
-			qt := c.parsedQuery[i]
-			val := arr.Children[qt.index].Value
+			return nil, nil, fmt.Errorf("%w: your query asked for key %s but found an array", ErrTypeMismatch, sel.name)
+		}
+		index := normalizeIndex(sel.index, len(n.Children))
+		if index < 0 || index >= len(n.Children) {
+			return nil, nil, fmt.Errorf("%w: index %d on an array of length %d", ErrIndexOutOfRange, sel.index, len(n.Children))
+		}
+		return ast.Unwrap(n.Children[index].Value), appendIndexToPath(path, index), nil
+	default:
+		return nil, nil, fmt.Errorf("%w: your query asked for a child of a %s", ErrTypeMismatch, kindOf(node))
+	}
+}
 
-			if v2, ok := val.(ast.ValueContent); ok {
-				// unwrap the Value
-				val = v2
-			}
+// resolveAll is resolveQuery for queries that can match more than one value. Each segment is
+// applied to every node matched so far, and nodes a selector doesn't apply to (a missing key, or a key
+// asked of an array) drop out rather than failing the query. Matches are returned in document order.
+func (c *Client) resolveAll(segments []segment) []ast.ValueContent {
+	root := ast.Unwrap(c.tree.RootValue.Content)
+	return selectNodes(root, root, segments)
+}
 
-			switch v := val.(type) {
-			case ast.Object:
-				obj = v
-				currentType = ast.ObjectType
-				break
-			case ast.Array:
-				arr = v
-				currentType = ast.ArrayType
-				break
-			case ast.Literal:
-				// If we're on the final value, return it
-				if i == parsedQueryLen-1 {
-					c.setResultFromLiteral(v.Value)
-				} else {
-					return errors.New("Sorry, it looks like your query isn't quite right")
-				}
+// selectNodes applies query segments starting from node. The root is the document's root value, which
+// filters can refer to with `$`.
+func selectNodes(root, node ast.ValueContent, segments []segment) []ast.ValueContent {
+	current := []ast.ValueContent{node}
+
+	for _, seg := range segments {
+		var next []ast.ValueContent
+		for _, node := range current {
+			if seg.descendant {
+				next = appendDescendantsSelected(next, root, node, seg.selectors)
+			} else {
+				next = appendSelected(next, root, node, seg.selectors)
 			}
 		}
+		current = next
 	}
 
-	return nil
+	return current
 }
 
-// setFinalValue is called when we are on the final queryToken. It handles narrowing down what
-// needs to be returned and sets the result to the Client
-func (c *Client) setFinalValue(currentType ast.Type, index int, obj ast.Object, arr ast.Array) {
-	if currentType == ast.ObjectType {
-		r := c.parsedQuery[index].key
-		for _, v := range obj.Children {
-			if r == v.Key.Value {
-				c.setResultFromValue(v.Value)
-				break
+// appendSelected appends the children of node picked by each of the selectors in turn. A child picked
+// by more than one selector of a union is appended once for each.
+func appendSelected(selected []ast.ValueContent, root, node ast.ValueContent, selectors []selector) []ast.ValueContent {
+	for _, sel := range selectors {
+		switch n := node.(type) {
+		case ast.Object:
+			for _, prop := range n.Children {
+				value := ast.Unwrap(prop.Value)
+				switch sel.kind {
+				case wildcardSelector:
+					selected = append(selected, value)
+				case filterSelector:
+					if sel.filter.eval(root, value) {
+						selected = append(selected, value)
+					}
+				case nameSelector:
+					if keyMatches(prop.Key, sel.name) {
+						selected = append(selected, value)
+					}
+				}
+			}
+		case ast.Array:
+			switch sel.kind {
+			case wildcardSelector:
+				for _, item := range n.Children {
+					selected = append(selected, ast.Unwrap(item.Value))
+				}
+			case filterSelector:
+				for _, item := range n.Children {
+					if value := ast.Unwrap(item.Value); sel.filter.eval(root, value) {
+						selected = append(selected, value)
+					}
+				}
+			case sliceSelector:
+				for _, index := range sel.slice.indices(len(n.Children)) {
+					selected = append(selected, ast.Unwrap(n.Children[index].Value))
+				}
+			case indexSelector:
+				if index := normalizeIndex(sel.index, len(n.Children)); index >= 0 && index < len(n.Children) {
+					selected = append(selected, ast.Unwrap(n.Children[index].Value))
+				}
 			}
 		}
-		return
 	}
-	ind := c.parsedQuery[index].index
-	c.setResultFromValue(arr.Children[ind])
+	return selected
 }
 
-// setResultFromValue switches on an ast.Value type and assigns the appropriate result to the client
-func (c *Client) setResultFromValue(value ast.ValueContent) {
-	if v2, ok := value.(ast.ArrayItem); ok {
-		// unwrap the ArrayItem
-		value = v2.Value
+// keyMatches reports whether an object key is the same as key once its escape sequences are decoded.
+func keyMatches(ident ast.Identifier, key string) bool {
+	if strings.IndexByte(ident.Value, '\\') < 0 {
+		return ident.Value == key
 	}
-	if v2, ok := value.(ast.Value); ok {
-		// unwrap the Value
-		value = v2.Content
+	decoded, err := ident.Decoded()
+	return err == nil && decoded == key
+}
+
+// appendDescendantsSelected applies selectors to node and to every node beneath it, visiting
+// parents before their children so matches stay in document order. Nodes are walked in place.
+func appendDescendantsSelected(selected []ast.ValueContent, root, node ast.ValueContent, selectors []selector) []ast.ValueContent {
+	selected = appendSelected(selected, root, node, selectors)
+
+	switch n := node.(type) {
+	case ast.Object:
+		for _, prop := range n.Children {
+			selected = appendDescendantsSelected(selected, root, ast.Unwrap(prop.Value), selectors)
+		}
+	case ast.Array:
+		for _, item := range n.Children {
+			selected = appendDescendantsSelected(selected, root, ast.Unwrap(item.Value), selectors)
+		}
 	}
-	switch val := value.(type) {
+
+	return selected
+}
+
+// resultFromValue switches on an ast.Value type and returns the appropriate result
+func (c *Client) resultFromValue(value ast.ValueContent) string {
+	switch val := ast.Unwrap(value).(type) {
 	case ast.Literal:
-		c.setResultFromLiteral(val.Value)
+		return resultFromLiteral(val.Value)
 	case ast.Object:
-		c.result = string(c.input[val.Start:val.End])
+		return string(c.input[val.Start:val.End])
 	case ast.Array:
-		c.result = string(c.input[val.Start:val.End])
+		return string(c.input[val.Start:val.End])
 	}
+	return ""
 }
 
-// setResultFromLiteral is very similar to setResultFromValue, except it we know the value we're switching over
-// must be a Literal, meaning the assigned result will either be a string, number, boolean, or null
-func (c *Client) setResultFromLiteral(value ast.ValueContent) {
+// resultFromLiteral is very similar to resultFromValue, except it we know the value we're switching over
+// must be a Literal, meaning the result will either be a string, number, boolean, or null
+func resultFromLiteral(value ast.ValueContent) string {
 	switch lit := value.(type) {
 	case string:
-		c.result = lit
+		return lit
 	case float64:
-		c.result = fmt.Sprintf("%f", lit)
+		return fmt.Sprintf("%f", lit)
 	case int, int64:
-		c.result = fmt.Sprintf("%d", lit)
+		return fmt.Sprintf("%d", lit)
 	case bool:
-		c.result = fmt.Sprintf("%v", lit)
+		return fmt.Sprintf("%v", lit)
 	case nil:
-		c.result = "null"
-	}
-}
-
-// validateQueryRoot handles some very simple validation around the root of the query
-func validateQueryRoot(query string, rootNodeType ast.RootNodeType) error {
-	if query[0] != '$' {
-		return ErrNoDollarSignRoot
+		return "null"
 	}
-
-	// The query root after the `$` must be a `.` if the rootNodeType is an object
-	validObjQueryRoot := query[1] == '.'
-	if rootNodeType == ast.ObjectRoot && !validObjQueryRoot {
-		return ErrWrongObjectRootSelector
-	}
-
-	// The query root after the `$` must be a `[` if the rootNodeType is an array
-	validArrayQueryRoot := query[1] == '['
-	if rootNodeType == ast.ArrayRoot && !validArrayQueryRoot {
-		return ErrWrongArrayRootSelector
-	}
-
-	return nil
-}
-
-func errSelectorSytax(operator string) error {
-	return fmt.Errorf(
-		"Error parsing query, expected either a `.` for selections on an object or a `[` for selections on an array. Got: %s",
-		operator,
-	)
+	return ""
 }
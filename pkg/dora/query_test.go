@@ -0,0 +1,202 @@
+package dora
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQueryError(t *testing.T) {
+	c, err := NewFromString(TestJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	tests := [...]struct {
+		query    string
+		expected error
+		pos      int
+		path     string
+	}{
+		{query: "", expected: ErrQuerySyntax},
+		{query: "$", expected: nil},
+		{query: "data", expected: ErrNoDollarSignRoot},
+		{query: "$.data.", expected: ErrQuerySyntax, pos: 7},
+		{query: "$.data[01]", expected: ErrQuerySyntax, pos: 9},
+		{query: "$.data.people", expected: ErrKeyNotFound, pos: 6, path: "$.data"},
+		{query: "$.data.users[3].email", expected: ErrIndexOutOfRange, pos: 12, path: "$.data.users"},
+		{query: "$[0]", expected: ErrWrongObjectRootSelector, pos: 1, path: "$"},
+		{query: "$[0]", expected: ErrTypeMismatch, pos: 1, path: "$"},
+		{query: "$.data.users.email", expected: ErrTypeMismatch, pos: 12, path: "$.data.users"},
+		{query: "$.data[0]", expected: ErrTypeMismatch, pos: 6, path: "$.data"},
+		{query: "$.date.year", expected: ErrTypeMismatch, pos: 6, path: "$.date"},
+		{query: "$.date[0]", expected: ErrTypeMismatch, pos: 6, path: "$.date"},
+		{query: "$.data.users[0]..email", expected: ErrMultipleValues, pos: 15, path: "$.data.users[0]"},
+		{query: "$.codes[::-1]", expected: ErrMultipleValues, pos: 7, path: "$.codes"},
+		{query: "$[*].users", expected: ErrMultipleValues, pos: 1, path: "$"},
+		{query: "$.missing[*]", expected: ErrKeyNotFound, pos: 1, path: "$"},
+	}
+
+	for _, tt := range tests {
+		_, err := c.GetString(tt.query)
+		if tt.expected == nil {
+			if err != nil {
+				t.Fatalf("Expected no error from %q, got: %v", tt.query, err)
+			}
+			continue
+		}
+		if !errors.Is(err, tt.expected) {
+			t.Fatalf("Expected %q to fail with %v, got: %v", tt.query, tt.expected, err)
+		}
+		var queryErr *QueryError
+		if !errors.As(err, &queryErr) {
+			t.Fatalf("Expected a *QueryError from %q, got: %T", tt.query, err)
+		}
+		if queryErr.Query != tt.query || queryErr.Pos != tt.pos || queryErr.Path != tt.path {
+			t.Fatalf("Expected %q to fail at %d after %q, got: %+v", tt.query, tt.pos, tt.path, queryErr)
+		}
+	}
+
+	_, err = c.GetString("$.data.users[0].first_name.x")
+	expected := "Error running query $.data.users[0].first_name.x at 26, after $.data.users[0].first_name. " +
+		"Query step doesn't match the type of the value: your query asked for a child of a string"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected error %q, got: %v", expected, err)
+	}
+}
+
+func TestQueryErrorBeyondGetString(t *testing.T) {
+	c, err := NewFromString(TestJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	tests := [...]struct {
+		name     string
+		run      func() error
+		expected error
+		pos      int
+		path     string
+	}{
+		{name: "Project without keys", expected: ErrNotProjection, pos: 12, run: func() error {
+			_, err := c.Project("$.data.users[0]")
+			return err
+		}},
+		{name: "Project without keys", expected: ErrQuerySyntax, pos: 12, run: func() error {
+			_, err := c.Project("$.data.users[0]")
+			return err
+		}},
+		{name: "Project on an array", expected: ErrTypeMismatch, pos: 7, path: "$.codes", run: func() error {
+			_, err := c.Project("$.codes['a']")
+			return err
+		}},
+		{name: "PathToPointer with a wildcard", expected: ErrMultipleValues, pos: 12, run: func() error {
+			_, err := PathToPointer("$.data.users[*]")
+			return err
+		}},
+		{name: "PathToPointer with a negative index", expected: ErrIndexOutOfRange, pos: 7, run: func() error {
+			_, err := PathToPointer("$.codes[-1]")
+			return err
+		}},
+		{name: "GetPointer without a slash", expected: ErrInvalidPointer, run: func() error {
+			_, err := c.GetPointer("data")
+			return err
+		}},
+		{name: "GetPointer with a bad escape", expected: ErrQuerySyntax, pos: 5, run: func() error {
+			_, err := c.GetPointer("/data~2")
+			return err
+		}},
+		{name: "GetPointer with a missing key", expected: ErrKeyNotFound, pos: 5, path: "$.data", run: func() error {
+			_, err := c.GetPointer("/data/people")
+			return err
+		}},
+		{name: "GetInt of a string", expected: ErrTypeMismatch, pos: 26, path: "$.data.users[0].first_name", run: func() error {
+			_, err := c.GetInt("$.data.users[0].first_name")
+			return err
+		}},
+		{name: "GetInt of a fraction", expected: ErrTypeMismatch, pos: 10, path: "$.codes[4]", run: func() error {
+			_, err := c.GetInt("$.codes[4]")
+			return err
+		}},
+		{name: "GetBool of a string", expected: ErrTypeMismatch, pos: 6, path: "$.date", run: func() error {
+			_, err := c.GetBool("$.date")
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		err := tt.run()
+		if !errors.Is(err, tt.expected) {
+			t.Fatalf("%s: expected %v, got: %v", tt.name, tt.expected, err)
+		}
+		var queryErr *QueryError
+		if !errors.As(err, &queryErr) {
+			t.Fatalf("%s: expected a *QueryError, got: %T", tt.name, err)
+		}
+		if queryErr.Pos != tt.pos || queryErr.Path != tt.path {
+			t.Fatalf("%s: expected to fail at %d after %q, got: %+v", tt.name, tt.pos, tt.path, queryErr)
+		}
+	}
+}
+
+func TestQueryErrorFromDecoding(t *testing.T) {
+	c, err := NewFromString(`{ "s": "a\qb", "o": { "\q": 1 }, "huge": 1e400 }`)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+	query := func(q string) Result {
+		r, err := c.Query(q)
+		if err != nil {
+			t.Fatalf("Failed to run query %s. Error: %v", q, err)
+		}
+		return r
+	}
+
+	tests := [...]struct {
+		name string
+		run  func() error
+		path string
+	}{
+		{name: "Len of a bad escape", path: "$.s", run: func() error { _, err := c.Len("$.s"); return err }},
+		{name: "Keys with a bad escape", path: "$.o", run: func() error { _, err := c.Keys("$.o"); return err }},
+		{name: "Result.Str of a bad escape", path: "$['s']", run: func() error { _, err := query("$.s").Str(); return err }},
+		{name: "Result.Object with a bad escape", path: "$['o']", run: func() error { _, err := query("$.o").Object(); return err }},
+		{name: "Result.Float64 out of range", path: "$['huge']", run: func() error { _, err := query("$.huge").Float64(); return err }},
+	}
+
+	for _, tt := range tests {
+		var queryErr *QueryError
+		if err := tt.run(); !errors.As(err, &queryErr) || queryErr.Path != tt.path {
+			t.Fatalf("%s: expected a *QueryError with path %s, got: %v", tt.name, tt.path, err)
+		}
+	}
+}
+
+// FuzzQuery checks that no query, however malformed, panics.
+func FuzzQuery(f *testing.F) {
+	for _, seed := range []string{
+		"$", "", "$.", "$[", "$..", "$.data.users[0].email", "$..[?(@.age > 21 && @.confirmed == true)]",
+		"$.codes[-1:0:-2]", "$['a','b'][0,*]", "$[?length(@.name) > count(@.*)]", "$[?match(@, '[')]",
+		"$.codes[9007199254740991]", "$[?(!(@.a == 'x\\u00e9'))]", "$[?value(@..c) == 1e400]",
+	} {
+		f.Add(seed)
+	}
+
+	c, err := NewFromString(TestJSON)
+	if err != nil {
+		f.Fatalf("\nError creating client: %v\n", err)
+	}
+	arr, err := NewFromString(`[1, "two", [3], { "four": null }]`)
+	if err != nil {
+		f.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	f.Fuzz(func(t *testing.T, query string) {
+		for _, client := range []*Client{c, arr} {
+			client.GetString(query)
+			client.GetAll(query)
+			client.Paths(query)
+			client.Project(query)
+			client.Query(query)
+		}
+	})
+}
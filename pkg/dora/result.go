@@ -0,0 +1,198 @@
+package dora
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/bradford-hamilton/dora/pkg/ast"
+)
+
+// The kinds of JSON value a Result can hold
+const (
+	KindObject Kind = iota
+	KindArray
+	KindString
+	KindNumber
+	KindBool
+	KindNull
+)
+
+// Kind is the kind of JSON value a query matched.
+type Kind int
+
+// String returns a readable name for the Kind.
+func (k Kind) String() string {
+	switch k {
+	case KindObject:
+		return "object"
+	case KindArray:
+		return "array"
+	case KindString:
+		return "string"
+	case KindNumber:
+		return "number"
+	case KindBool:
+		return "bool"
+	case KindNull:
+		return "null"
+	default:
+		return "unknown"
+	}
+}
+
+// Result is a value a query matched, with everything dora knows about it. The value is only
+// converted into Go values when asked for, so matching large or many values stays cheap.
+type Result struct {
+	Kind Kind     // The kind of the value
+	Raw  []byte   // The value's source text, exactly as it appears in the document. It must not be modified
+	Path string   // The normalized path of the value, ex: `$['data']['users'][0]['email']`
+	Span ast.Span // The source range of the value
+
+	node ast.ValueContent
+}
+
+// Query resolves a query and returns the value it points at as a Result.
+func (c *Client) Query(query string) (Result, error) {
+	node, err := c.resolve(query)
+	if err != nil {
+		return Result{}, err
+	}
+	return c.newResult(node), nil
+}
+
+// QueryAll returns every value a query matches as Results, in document order.
+func (c *Client) QueryAll(query string) ([]Result, error) {
+	q, err := Compile(query)
+	if err != nil {
+		return nil, err
+	}
+	nodes := c.resolveAll(q.segments)
+	results := make([]Result, 0, len(nodes))
+	for _, node := range nodes {
+		results = append(results, c.newResult(node))
+	}
+	return results, nil
+}
+
+func (c *Client) newResult(node ast.ValueContent) Result {
+	span := ast.SpanOf(node)
+	root := ast.Unwrap(c.tree.RootValue.Content)
+	return Result{
+		Kind: kindOf(node),
+		Raw:  c.input[span.Start.Offset:span.End.Offset],
+		Path: string(normalizedPath(root, node, []byte{'$'})),
+		Span: span,
+		node: node,
+	}
+}
+
+// kindOf returns the Kind of an ast.Object, ast.Array, or ast.Literal.
+func kindOf(node ast.ValueContent) Kind {
+	switch n := node.(type) {
+	case ast.Object:
+		return KindObject
+	case ast.Array:
+		return KindArray
+	case ast.Literal:
+		switch n.ValueType {
+		case ast.StringLiteralValueType:
+			return KindString
+		case ast.NumberLiteralValueType:
+			return KindNumber
+		case ast.BooleanLiteralValueType:
+			return KindBool
+		}
+	}
+	return KindNull
+}
+
+// Value returns the value converted into Go values, the way Client.Value converts it.
+func (r Result) Value() (any, error) {
+	value, err := nodeToValue(r.node, &valueOptions{})
+	if err != nil {
+		return nil, valueError(r.Path, err)
+	}
+	return value, nil
+}
+
+// Str returns the value of a string Result.
+func (r Result) Str() (string, error) {
+	if err := r.expect(KindString); err != nil {
+		return "", err
+	}
+	s, err := r.node.(ast.Literal).Decoded()
+	if err != nil {
+		return "", valueError(r.Path, err)
+	}
+	return s, nil
+}
+
+// Float64 returns the value of a number Result. Numbers too large for a float64 are an error.
+func (r Result) Float64() (float64, error) {
+	if err := r.expect(KindNumber); err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(r.node.(ast.Literal).NumberText(), 64)
+	if err != nil {
+		return 0, valueError(r.Path, fmt.Errorf("%w: %v", ErrTypeMismatch, err))
+	}
+	return f, nil
+}
+
+// Int64 returns the value of a number Result that is an integer. It's parsed from the source text,
+// so integers too large for a float64 to hold exactly come back as written, and like GetInt64,
+// numbers written with a fraction or exponent are allowed as long as they're whole, ex: `1e3`.
+func (r Result) Int64() (int64, error) {
+	if err := r.expect(KindNumber); err != nil {
+		return 0, err
+	}
+	n, err := toInt(string(r.Raw))
+	if err != nil {
+		return 0, valueError(r.Path, fmt.Errorf("%w: %v", ErrTypeMismatch, err))
+	}
+	return n, nil
+}
+
+// Bool returns the value of a boolean Result.
+func (r Result) Bool() (bool, error) {
+	if err := r.expect(KindBool); err != nil {
+		return false, err
+	}
+	return r.node.(ast.Literal).Value == true, nil
+}
+
+// Object returns the value of an object Result.
+func (r Result) Object() (map[string]any, error) {
+	if err := r.expect(KindObject); err != nil {
+		return nil, err
+	}
+	value, err := r.Value()
+	if err != nil {
+		return nil, err
+	}
+	return value.(map[string]any), nil
+}
+
+// Array returns the value of an array Result.
+func (r Result) Array() ([]any, error) {
+	if err := r.expect(KindArray); err != nil {
+		return nil, err
+	}
+	value, err := r.Value()
+	if err != nil {
+		return nil, err
+	}
+	return value.([]any), nil
+}
+
+// IsNull reports whether the Result is null.
+func (r Result) IsNull() bool {
+	return r.Kind == KindNull
+}
+
+func (r Result) expect(kind Kind) error {
+	if r.Kind != kind {
+		return valueError(r.Path, fmt.Errorf("%w: %s is %s, not %s", ErrTypeMismatch, r.Path, r.Kind, kind))
+	}
+	return nil
+}
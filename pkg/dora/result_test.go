@@ -0,0 +1,157 @@
+package dora
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestClient_Query(t *testing.T) {
+	c, err := NewFromString(TestJSON)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	tests := [...]struct {
+		query string
+		kind  Kind
+		raw   string
+		value any
+		path  string
+	}{
+		{query: "$.data.users[0].email", kind: KindString, raw: `"brad@example.com"`, value: "brad@example.com", path: "$['data']['users'][0]['email']"},
+		{query: "$.data.users[0].age", kind: KindNumber, raw: "30", value: 30.0, path: "$['data']['users'][0]['age']"},
+		{query: "$.codes[4]", kind: KindNumber, raw: "404.567", value: 404.567, path: "$['codes'][4]"},
+		{query: "$.enabled", kind: KindBool, raw: "true", value: true, path: "$['enabled']"},
+		{query: "$.data.users[0].allergies", kind: KindNull, raw: "null", value: nil, path: "$['data']['users'][0]['allergies']"},
+		{query: "$.data.users[0].random_items", kind: KindArray, raw: `[true, { "dog_name": "ellie" }]`, value: []any{true, map[string]any{"dog_name": "ellie"}}, path: "$['data']['users'][0]['random_items']"},
+		{query: "$.data.users[0].random_items[1]", kind: KindObject, raw: `{ "dog_name": "ellie" }`, value: map[string]any{"dog_name": "ellie"}, path: "$['data']['users'][0]['random_items'][1]"},
+	}
+
+	for _, tt := range tests {
+		r, err := c.Query(tt.query)
+		if err != nil {
+			t.Fatalf("Failed to run query %s. Error: %v", tt.query, err)
+		}
+		if r.Kind != tt.kind {
+			t.Fatalf("Expected %s to be %s, got: %s", tt.query, tt.kind, r.Kind)
+		}
+		if string(r.Raw) != tt.raw {
+			t.Fatalf("Expected %s to have raw text %s, got: %s", tt.query, tt.raw, r.Raw)
+		}
+		if value, err := r.Value(); err != nil || !reflect.DeepEqual(value, tt.value) {
+			t.Fatalf("Expected %s to have value %#v, got: %#v, %v", tt.query, tt.value, value, err)
+		}
+		if r.Path != tt.path {
+			t.Fatalf("Expected %s to have path %s, got: %s", tt.query, tt.path, r.Path)
+		}
+		if TestJSON[r.Span.Start.Offset:r.Span.End.Offset] != tt.raw {
+			t.Fatalf("Expected %s to have a span around %s", tt.query, tt.raw)
+		}
+	}
+
+	if _, err := c.Query("$.codes[*]"); !errors.Is(err, ErrMultipleValues) {
+		t.Fatalf("Expected ErrMultipleValues, got: %v", err)
+	}
+
+	results, err := c.QueryAll("$.codes[?(@ > 400)]")
+	if err != nil {
+		t.Fatalf("Failed to run QueryAll. Error: %v", err)
+	}
+	if len(results) != 2 || results[0].Path != "$['codes'][3]" || string(results[1].Raw) != "404.567" {
+		t.Fatalf("Unexpected results from QueryAll: %+v", results)
+	}
+}
+
+func TestResult_Accessors(t *testing.T) {
+	c, err := NewFromString(`{ "s": "str", "n": 9007199254740993, "f": 1.5, "e": 1e3, "big": 1e19, "b": false, "o": { "a": 1 }, "a": [1], "z": null }`)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+	query := func(q string) Result {
+		r, err := c.Query(q)
+		if err != nil {
+			t.Fatalf("Failed to run query %s. Error: %v", q, err)
+		}
+		return r
+	}
+
+	if s, err := query("$.s").Str(); err != nil || s != "str" {
+		t.Fatalf("Expected str, got: %s, %v", s, err)
+	}
+	if n, err := query("$.n").Int64(); err != nil || n != 9007199254740993 {
+		t.Fatalf("Expected 9007199254740993, got: %d, %v", n, err)
+	}
+	if f, err := query("$.f").Float64(); err != nil || f != 1.5 {
+		t.Fatalf("Expected 1.5, got: %f, %v", f, err)
+	}
+	if n, err := query("$.e").Int64(); err != nil || n != 1000 {
+		t.Fatalf("Expected 1000, got: %d, %v", n, err)
+	}
+	if n, err := query("$.f").Int64(); err == nil || n != 0 {
+		t.Fatalf("Expected an error and 0 getting 1.5 as an int64, got: %d, %v", n, err)
+	}
+	if n, err := query("$.big").Int64(); err == nil || n != 0 {
+		t.Fatalf("Expected an error and 0 getting 1e19 as an int64, got: %d, %v", n, err)
+	}
+	if b, err := query("$.b").Bool(); err != nil || b {
+		t.Fatalf("Expected false, got: %t, %v", b, err)
+	}
+	if o, err := query("$.o").Object(); err != nil || !reflect.DeepEqual(o, map[string]any{"a": 1.0}) {
+		t.Fatalf("Expected an object, got: %v, %v", o, err)
+	}
+	if a, err := query("$.a").Array(); err != nil || !reflect.DeepEqual(a, []any{1.0}) {
+		t.Fatalf("Expected an array, got: %v, %v", a, err)
+	}
+	if !query("$.z").IsNull() || query("$.s").IsNull() {
+		t.Fatalf("Expected only $.z to be null")
+	}
+
+	mismatches := []func() error{
+		func() error { _, err := query("$.n").Str(); return err },
+		func() error { _, err := query("$.s").Float64(); return err },
+		func() error { _, err := query("$.b").Int64(); return err },
+		func() error { _, err := query("$.z").Bool(); return err },
+		func() error { _, err := query("$.a").Object(); return err },
+		func() error { _, err := query("$.o").Array(); return err },
+	}
+	for i, mismatch := range mismatches {
+		if err := mismatch(); !errors.Is(err, ErrTypeMismatch) {
+			t.Fatalf("Expected ErrTypeMismatch from mismatch %d, got: %v", i, err)
+		}
+	}
+	_, err = query("$.s").Bool()
+	var queryErr *QueryError
+	if !errors.As(err, &queryErr) || queryErr.Path != "$['s']" {
+		t.Fatalf("Expected a *QueryError for $['s'], got: %v", err)
+	}
+	if err.Error() != "Error running query $['s'] at 6, after $['s']. Query step doesn't match the type of the value: $['s'] is string, not bool" {
+		t.Fatalf("Unexpected error message: %v", err)
+	}
+}
+
+func TestClient_QueryOutOfRangeNumber(t *testing.T) {
+	c, err := NewFromString(`{ "huge": 1e400, "list": [1, 2] }`)
+	if err != nil {
+		t.Fatalf("\nError creating client: %v\n", err)
+	}
+
+	// Values are converted when asked for, so a number a float64 can't hold doesn't fail the query
+	root, err := c.Query("$")
+	if err != nil || root.Kind != KindObject {
+		t.Fatalf("Expected the root object, got: %+v, %v", root, err)
+	}
+	results, err := c.QueryAll("$..*")
+	if err != nil || len(results) != 4 {
+		t.Fatalf("Expected 4 results from QueryAll, got: %+v, %v", results, err)
+	}
+	if string(results[0].Raw) != "1e400" || results[3].Path != "$['list'][1]" {
+		t.Fatalf("Unexpected results from QueryAll: %+v", results)
+	}
+	if _, err := results[0].Float64(); err == nil {
+		t.Fatalf("Expected an error converting 1e400 to a float64")
+	}
+	if _, err := root.Value(); err == nil {
+		t.Fatalf("Expected an error converting an object holding 1e400 to Go values")
+	}
+}
@@ -0,0 +1,197 @@
+package dora
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/bradford-hamilton/dora/pkg/ast"
+)
+
+// Number represents a JSON number exactly as it was written in the source. It's
+// returned in place of float64 when the UseNumber option is passed.
+type Number string
+
+// String returns the number's source text.
+func (n Number) String() string {
+	return string(n)
+}
+
+// Float64 returns the number as a float64.
+func (n Number) Float64() (float64, error) {
+	return strconv.ParseFloat(string(n), 64)
+}
+
+// Int64 returns the number as an int64.
+func (n Number) Int64() (int64, error) {
+	return strconv.ParseInt(string(n), 10, 64)
+}
+
+// OrderedMap is a JSON object that remembers the order its keys appeared in. It's what
+// OrderedValue returns for objects.
+type OrderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+// Keys returns the object's keys in source order.
+func (m *OrderedMap) Keys() []string {
+	return m.keys
+}
+
+// Get returns the value for key and whether the key exists.
+func (m *OrderedMap) Get(key string) (any, bool) {
+	v, ok := m.values[key]
+	return v, ok
+}
+
+// Len returns the number of keys in the object.
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// MarshalJSON writes the object with its keys in source order.
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (m *OrderedMap) set(key string, value any) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// ValueOption configures how Value and OrderedValue convert JSON into Go values.
+type ValueOption func(*valueOptions)
+
+type valueOptions struct {
+	useNumber bool
+	ordered   bool
+}
+
+// UseNumber makes numbers convert to Number instead of float64, so large integers and
+// precise decimals don't lose anything on the way out.
+func UseNumber() ValueOption {
+	return func(o *valueOptions) {
+		o.useNumber = true
+	}
+}
+
+// Value resolves a query and converts the value it points at into native Go values, built
+// straight from the AST: objects become map[string]any, arrays []any, strings string,
+// numbers float64 (or Number with UseNumber), booleans bool, and null nil.
+func (c *Client) Value(query string, opts ...ValueOption) (any, error) {
+	var o valueOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return c.value(query, o)
+}
+
+// OrderedValue is the same as Value, except objects become *OrderedMap so their keys stay in
+// source order.
+func (c *Client) OrderedValue(query string, opts ...ValueOption) (any, error) {
+	o := valueOptions{ordered: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return c.value(query, o)
+}
+
+func (c *Client) value(query string, o valueOptions) (any, error) {
+	node, err := c.resolve(query)
+	if err != nil {
+		return nil, err
+	}
+	return nodeToValue(node, &o)
+}
+
+// nodeToValue converts an AST node into native Go values.
+func nodeToValue(node ast.ValueContent, o *valueOptions) (any, error) {
+	switch n := ast.Unwrap(node).(type) {
+	case ast.Object:
+		if o.ordered {
+			m := &OrderedMap{values: make(map[string]any, len(n.Children))}
+			for _, prop := range n.Children {
+				key, value, err := propertyToValue(prop, o)
+				if err != nil {
+					return nil, err
+				}
+				m.set(key, value)
+			}
+			return m, nil
+		}
+		m := make(map[string]any, len(n.Children))
+		for _, prop := range n.Children {
+			key, value, err := propertyToValue(prop, o)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+		}
+		return m, nil
+	case ast.Array:
+		arr := make([]any, 0, len(n.Children))
+		for _, item := range n.Children {
+			value, err := nodeToValue(item.Value, o)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		return arr, nil
+	case ast.Literal:
+		return literalToValue(n, o)
+	default:
+		return nil, fmt.Errorf("unhandled node type %T", n)
+	}
+}
+
+func propertyToValue(prop ast.Property, o *valueOptions) (string, any, error) {
+	key, err := prop.Key.Decoded()
+	if err != nil {
+		return "", nil, err
+	}
+	value, err := nodeToValue(prop.Value, o)
+	if err != nil {
+		return "", nil, err
+	}
+	return key, value, nil
+}
+
+func literalToValue(lit ast.Literal, o *valueOptions) (any, error) {
+	switch lit.ValueType {
+	case ast.StringLiteralValueType:
+		return lit.Decoded()
+	case ast.NumberLiteralValueType:
+		if o.useNumber {
+			return Number(lit.NumberText()), nil
+		}
+		return strconv.ParseFloat(lit.NumberText(), 64)
+	case ast.BooleanLiteralValueType:
+		return lit.Value == true, nil
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,218 @@
+// Package jcs writes dora AST nodes using the JSON Canonicalization Scheme described in
+// RFC 8785. Canonical output is deterministic: object keys are sorted by their UTF-16 code
+// units, numbers use ECMAScript formatting, strings use minimal escaping, and there is no
+// whitespace. This makes it suitable for hashing and signing JSON payloads.
+package jcs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/bradford-hamilton/dora/pkg/ast"
+)
+
+var (
+	// ErrDuplicateKey is returned when an object contains the same key more than once. RFC 8785
+	// requires I-JSON input, which doesn't allow duplicate keys.
+	ErrDuplicateKey = errors.New("jcs: duplicate object key")
+	// ErrInvalidNumber is returned for numbers that can't be represented as an IEEE 754 double.
+	ErrInvalidNumber = errors.New("jcs: number is not a finite IEEE 754 double")
+	// ErrInvalidString is returned for strings with invalid escapes, unpaired surrogates, or invalid UTF-8.
+	ErrInvalidString = errors.New("jcs: string is not valid Unicode")
+)
+
+// Marshal returns the canonical form of node. The node may be a RootNode, Value, ArrayItem,
+// Object, Array, or Literal.
+func Marshal(node ast.ValueContent) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := appendValue(&buf, ast.Unwrap(node)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Write writes the canonical form of node to w.
+func Write(w io.Writer, node ast.ValueContent) error {
+	b, err := Marshal(node)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func appendValue(buf *bytes.Buffer, node ast.ValueContent) error {
+	switch n := node.(type) {
+	case ast.Object:
+		return appendObject(buf, n)
+	case ast.Array:
+		return appendArray(buf, n)
+	case ast.Literal:
+		return appendLiteral(buf, n)
+	default:
+		return fmt.Errorf("jcs: unhandled node type %T", node)
+	}
+}
+
+// member is an object member with its decoded key and the key's UTF-16 code units for sorting.
+type member struct {
+	key   string
+	utf16 []uint16
+	value ast.ValueContent
+}
+
+func appendObject(buf *bytes.Buffer, obj ast.Object) error {
+	members := make([]member, 0, len(obj.Children))
+	for _, prop := range obj.Children {
+		key, err := prop.Key.Decoded()
+		if err != nil || !utf8.ValidString(key) {
+			return fmt.Errorf("%w: key %q", ErrInvalidString, prop.Key.Value)
+		}
+		members = append(members, member{
+			key:   key,
+			utf16: utf16.Encode([]rune(key)),
+			value: ast.Unwrap(prop.Value),
+		})
+	}
+
+	slices.SortFunc(members, func(a, b member) int {
+		return slices.Compare(a.utf16, b.utf16)
+	})
+
+	buf.WriteByte('{')
+	for i, m := range members {
+		if i > 0 {
+			if m.key == members[i-1].key {
+				return fmt.Errorf("%w: %q", ErrDuplicateKey, m.key)
+			}
+			buf.WriteByte(',')
+		}
+		appendString(buf, m.key)
+		buf.WriteByte(':')
+		if err := appendValue(buf, m.value); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+
+	return nil
+}
+
+func appendArray(buf *bytes.Buffer, arr ast.Array) error {
+	buf.WriteByte('[')
+	for i, item := range arr.Children {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := appendValue(buf, ast.Unwrap(item.Value)); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte(']')
+	return nil
+}
+
+func appendLiteral(buf *bytes.Buffer, lit ast.Literal) error {
+	switch lit.ValueType {
+	case ast.StringLiteralValueType:
+		s, err := lit.Decoded()
+		if err != nil || !utf8.ValidString(s) {
+			return fmt.Errorf("%w: %q", ErrInvalidString, lit.Value)
+		}
+		appendString(buf, s)
+	case ast.NumberLiteralValueType:
+		f, err := strconv.ParseFloat(lit.NumberText(), 64)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidNumber, lit.NumberText())
+		}
+		num, err := FormatNumber(f)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(num)
+	case ast.BooleanLiteralValueType:
+		if lit.Value == true {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case ast.NullLiteralValueType:
+		buf.WriteString("null")
+	default:
+		return fmt.Errorf("jcs: unhandled literal value type %v", lit.ValueType)
+	}
+	return nil
+}
+
+// appendString writes s as a JSON string, escaping only what RFC 8785 requires: the quotation
+// mark, the reverse solidus, and control characters. Everything else is written as UTF-8.
+func appendString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+
+	buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case c == '\b':
+			buf.WriteString(`\b`)
+		case c == '\f':
+			buf.WriteString(`\f`)
+		case c == '\n':
+			buf.WriteString(`\n`)
+		case c == '\r':
+			buf.WriteString(`\r`)
+		case c == '\t':
+			buf.WriteString(`\t`)
+		case c < 0x20:
+			buf.WriteString(`\u00`)
+			buf.WriteByte(hex[c>>4])
+			buf.WriteByte(hex[c&0xf])
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// FormatNumber formats f the way ECMAScript's Number.prototype.toString does, which is
+// the number serialization RFC 8785 requires. NaN and infinities are rejected.
+func FormatNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", ErrInvalidNumber
+	}
+	if f == 0 {
+		return "0", nil // Covers -0 as well
+	}
+
+	var sign string
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+
+	// ECMAScript uses plain decimal notation between 1e-6 and 1e21, and exponential notation
+	// otherwise. Both use the shortest digits that round trip, as Go does with precision -1.
+	format := byte('e')
+	if f >= 1e-6 && f < 1e21 {
+		format = 'f'
+	}
+	num := strconv.FormatFloat(f, format, -1, 64)
+
+	// Go pads exponents to two digits, ex: `1e+09`, where ECMAScript writes `1e+9`
+	if i := strings.IndexByte(num, 'e'); i >= 0 && num[i+2] == '0' {
+		num = num[:i+2] + num[i+3:]
+	}
+
+	return sign + num, nil
+}
@@ -0,0 +1,135 @@
+package jcs
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/bradford-hamilton/dora/pkg/lexer"
+	"github.com/bradford-hamilton/dora/pkg/parser"
+)
+
+// Test vectors from RFC 8785 section 3.2.2 and 3.2.3.
+func TestMarshal(t *testing.T) {
+	tests := [...]struct {
+		input    string
+		expected string
+	}{
+		{
+			input: `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`,
+			expected: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			input: `{
+  "\u20ac": "Euro Sign",
+  "\r": "Carriage Return",
+  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+  "1": "One",
+  "\ud83d\ude00": "Emoji: Grinning Face",
+  "\u0080": "Control",
+  "\u00f6": "Latin Small Letter O With Diaeresis"
+}`,
+			expected: "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin Small Letter O With Diaeresis\"," +
+				"\"€\":\"Euro Sign\",\"😀\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{
+			input: `[
+	// comments and formatting are dropped
+	{ 'b': [ 1.0, -0, 1e-7 ], "a": { } },
+	[]
+]`,
+			expected: `[{"a":{},"b":[1,0,1e-7]},[]]`,
+		},
+	}
+
+	for _, tt := range tests {
+		tree, err := parser.New(lexer.New(tt.input)).ParseJSON()
+		if err != nil {
+			t.Fatalf("Failed to parse %s. Error: %v", tt.input, err)
+		}
+
+		out, err := Marshal(tree)
+		if err != nil {
+			t.Fatalf("Failed to canonicalize %s. Error: %v", tt.input, err)
+		}
+		if string(out) != tt.expected {
+			t.Fatalf("Expected canonical output of %s, got: %s", tt.expected, out)
+		}
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	tests := [...]struct {
+		input       string
+		expectedErr error
+	}{
+		{input: `{"a": 1, "b": 2, "a": 3}`, expectedErr: ErrDuplicateKey},
+		{input: `{"a": 1, "\u0061": 2}`, expectedErr: ErrDuplicateKey},
+		{input: `["\ud83d"]`, expectedErr: ErrInvalidString},
+		{input: `[1e400]`, expectedErr: ErrInvalidNumber},
+	}
+
+	for _, tt := range tests {
+		tree, err := parser.New(lexer.New(tt.input)).ParseJSON()
+		if err != nil {
+			t.Fatalf("Failed to parse %s. Error: %v", tt.input, err)
+		}
+
+		if _, err := Marshal(tree); !errors.Is(err, tt.expectedErr) {
+			t.Fatalf("Expected error %v for %s, got: %v", tt.expectedErr, tt.input, err)
+		}
+	}
+}
+
+// Test vectors from RFC 8785 appendix B.
+func TestFormatNumber(t *testing.T) {
+	tests := [...]struct {
+		bits     uint64
+		expected string
+	}{
+		{bits: 0x0000000000000000, expected: "0"},
+		{bits: 0x8000000000000000, expected: "0"},
+		{bits: 0x0000000000000001, expected: "5e-324"},
+		{bits: 0x8000000000000001, expected: "-5e-324"},
+		{bits: 0x7fefffffffffffff, expected: "1.7976931348623157e+308"},
+		{bits: 0xffefffffffffffff, expected: "-1.7976931348623157e+308"},
+		{bits: 0x4340000000000000, expected: "9007199254740992"},
+		{bits: 0xc340000000000000, expected: "-9007199254740992"},
+		{bits: 0x4430000000000000, expected: "295147905179352830000"},
+		{bits: 0x44b52d02c7e14af5, expected: "9.999999999999997e+22"},
+		{bits: 0x44b52d02c7e14af6, expected: "1e+23"},
+		{bits: 0x44b52d02c7e14af7, expected: "1.0000000000000001e+23"},
+		{bits: 0x444b1ae4d6e2ef4e, expected: "999999999999999700000"},
+		{bits: 0x444b1ae4d6e2ef4f, expected: "999999999999999900000"},
+		{bits: 0x444b1ae4d6e2ef50, expected: "1e+21"},
+		{bits: 0x3eb0c6f7a0b5ed8c, expected: "9.999999999999997e-7"},
+		{bits: 0x3eb0c6f7a0b5ed8d, expected: "0.000001"},
+		{bits: 0x41b3de4355555553, expected: "333333333.3333332"},
+		{bits: 0x41b3de4355555554, expected: "333333333.33333325"},
+		{bits: 0x41b3de4355555555, expected: "333333333.3333333"},
+		{bits: 0x41b3de4355555556, expected: "333333333.3333334"},
+		{bits: 0x41b3de4355555557, expected: "333333333.33333343"},
+		{bits: 0xbecbf647612f3696, expected: "-0.0000033333333333333333"},
+		{bits: 0x43143ff3c1cb0959, expected: "1424953923781206.2"},
+	}
+
+	for _, tt := range tests {
+		num, err := FormatNumber(math.Float64frombits(tt.bits))
+		if err != nil {
+			t.Fatalf("Failed to format %#016x. Error: %v", tt.bits, err)
+		}
+		if num != tt.expected {
+			t.Fatalf("Expected %#016x to format as %s, got: %s", tt.bits, tt.expected, num)
+		}
+	}
+
+	for _, bits := range [...]uint64{0x7fffffffffffffff, 0x7ff0000000000000, 0xfff0000000000000} {
+		if _, err := FormatNumber(math.Float64frombits(bits)); !errors.Is(err, ErrInvalidNumber) {
+			t.Fatalf("Expected ErrInvalidNumber for %#016x, got: %v", bits, err)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+package jsonpath
+
+import (
+	"strconv"
+
+	"github.com/bradford-hamilton/dora/pkg/danger"
+)
+
+// parseOr parses a filter expression, the part after the `?` of a filter selector. Filters follow
+// RFC 9535 section 2.3.5:
+//
+//	<or>         ::= <and> (S "||" S <and>)*
+//	<and>        ::= <basic> (S "&&" S <basic>)*
+//	<basic>      ::= ("!" S)? "(" S <or> S ")" | ("!" S)? <query> | ("!" S)? <function> | <comparison>
+//	<comparison> ::= <operand> S ("==" | "!=" | "<" | "<=" | ">" | ">=") S <operand>
+//	<operand>    ::= <number> | <string> | "true" | "false" | "null" | <singular query> | <function>
+//	<query>      ::= ("@" | "$") (S <segment>)*
+//	<function>   ::= <name> "(" S (<argument> (S "," S <argument>)*)? S ")"
+//
+// The parentheses in the common `[?(@.age > 21)]` form are simply a grouping.
+func (p *parser) parseOr() (Expr, error) {
+	var operands []Expr
+	for {
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, expr)
+		if !p.consumeOperator("||") {
+			break
+		}
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return OrExpr{Operands: operands}, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	var operands []Expr
+	for {
+		expr, err := p.parseBasic()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, expr)
+		if !p.consumeOperator("&&") {
+			break
+		}
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return AndExpr{Operands: operands}, nil
+}
+
+func (p *parser) parseBasic() (Expr, error) {
+	p.skipWhitespace()
+
+	if p.peekIs('!') {
+		p.pos++
+		p.skipWhitespace()
+		expr, err := p.parseNegatable()
+		if err != nil {
+			return nil, err
+		}
+		return NotExpr{Expr: expr}, nil
+	}
+	if p.peekIs('(') {
+		return p.parseGroup()
+	}
+
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	op := p.comparisonOperator()
+	if op == "" {
+		if !isTest(left) {
+			return nil, p.errorf("expected a comparison operator")
+		}
+		return left, nil
+	}
+	if !isOperand(left) {
+		return nil, p.errorf("the left side of %s must be a literal, a singular query, or a function returning a value", op)
+	}
+
+	p.skipWhitespace()
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if !isOperand(right) {
+		return nil, p.errorf("the right side of %s must be a literal, a singular query, or a function returning a value", op)
+	}
+
+	return ComparisonExpr{Left: left, Op: op, Right: right}, nil
+}
+
+// parseNegatable parses what can follow a `!`: a group, or a query or function used as a test.
+func (p *parser) parseNegatable() (Expr, error) {
+	if p.peekIs('(') {
+		return p.parseGroup()
+	}
+	expr, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if !isTest(expr) {
+		return nil, p.errorf("expected `(`, a query, or a function returning a logical value after `!`")
+	}
+	return expr, nil
+}
+
+// parseGroup parses a parenthesized expression.
+func (p *parser) parseGroup() (Expr, error) {
+	p.pos++
+	p.skipWhitespace()
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipWhitespace()
+	if !p.peekIs(')') {
+		return nil, p.errorf("expected `)`")
+	}
+	p.pos++
+	return expr, nil
+}
+
+// parsePrimary parses a literal, query, or function call. What it can be used as is checked by the
+// caller with isOperand and isTest.
+func (p *parser) parsePrimary() (Expr, error) {
+	if p.pos >= len(p.input) {
+		return nil, p.errorf("unexpected end of filter")
+	}
+
+	switch char := p.input[p.pos]; {
+	case char == '@' || char == '$':
+		return p.parseFilterQuery()
+	case char == '\'' || char == '"':
+		s, err := p.parseString()
+		return Literal{Value: s}, err
+	case char == '-' || isNumber(char):
+		return p.parseNumber()
+	case 'a' <= char && char <= 'z':
+		return p.parseWord()
+	default:
+		return nil, p.errorf("unexpected %q in filter", char)
+	}
+}
+
+// isOperand reports whether expr produces a single value, so it can be compared: a literal, a query
+// that can only match one value, or a function returning a value.
+func isOperand(expr Expr) bool {
+	switch e := expr.(type) {
+	case Literal:
+		return true
+	case FilterQuery:
+		return e.Singular()
+	case FunctionCall:
+		sig, ok := Function(e.Name)
+		return ok && sig.Result == ValueType
+	default:
+		return false
+	}
+}
+
+// isTest reports whether expr can stand alone as an expression: a query, or a function returning a
+// logical value or nodes.
+func isTest(expr Expr) bool {
+	switch e := expr.(type) {
+	case FilterQuery:
+		return true
+	case FunctionCall:
+		sig, ok := Function(e.Name)
+		return ok && sig.Result != ValueType
+	default:
+		return false
+	}
+}
+
+// parseWord parses a literal keyword or a function call.
+func (p *parser) parseWord() (Expr, error) {
+	start := p.pos
+	for p.pos < len(p.input) && isFunctionNameChar(p.input[p.pos]) {
+		p.pos++
+	}
+	word := string(p.input[start:p.pos])
+
+	if p.peekIs('(') {
+		return p.parseFunctionCall(word)
+	}
+
+	switch word {
+	case "true", "false":
+		return Literal{Value: word == "true"}, nil
+	case "null":
+		return Literal{}, nil
+	default:
+		p.pos = start
+		return nil, p.errorf("unexpected %q in filter", word)
+	}
+}
+
+// parseFunctionCall parses the arguments of a call to the named function, checking each argument can be
+// used as the type of its parameter. The parser is on the `(`.
+func (p *parser) parseFunctionCall(name string) (FunctionCall, error) {
+	sig, ok := Function(name)
+	if !ok {
+		return FunctionCall{}, p.errorf("unknown function %s", name)
+	}
+	p.pos++
+
+	call := FunctionCall{Name: name}
+	p.skipWhitespace()
+	for !p.peekIs(')') {
+		if len(call.Args) > 0 {
+			if !p.peekIs(',') {
+				return FunctionCall{}, p.errorf("expected `,` or `)` in the arguments of %s", name)
+			}
+			p.pos++
+			p.skipWhitespace()
+		}
+		if len(call.Args) == len(sig.Params) {
+			return FunctionCall{}, p.errorf("too many arguments to %s, it takes %d", name, len(sig.Params))
+		}
+
+		arg, err := p.parseFunctionArgument(name, sig.Params[len(call.Args)])
+		if err != nil {
+			return FunctionCall{}, err
+		}
+		call.Args = append(call.Args, arg)
+		p.skipWhitespace()
+	}
+	p.pos++
+
+	if len(call.Args) != len(sig.Params) {
+		return FunctionCall{}, p.errorf("not enough arguments to %s, it takes %d", name, len(sig.Params))
+	}
+	return call, nil
+}
+
+// parseFunctionArgument parses an argument for a parameter of the given type.
+func (p *parser) parseFunctionArgument(name string, param FunctionType) (Expr, error) {
+	if param == LogicalType {
+		return p.parseOr()
+	}
+
+	if p.peekIs('@') || p.peekIs('$') {
+		query, err := p.parseFilterQuery()
+		if err != nil {
+			return nil, err
+		}
+		if param == ValueType && !query.Singular() {
+			return nil, p.errorf("arguments to %s must select a single value", name)
+		}
+		return query, nil
+	}
+
+	arg, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if param == NodesType || !isOperand(arg) {
+		return nil, p.errorf("invalid argument to %s", name)
+	}
+	return arg, nil
+}
+
+func isFunctionNameChar(char byte) bool {
+	return 'a' <= char && char <= 'z' || char == '_' || isNumber(char)
+}
+
+// parseFilterQuery parses a query inside a filter, starting at its `@` or `$`.
+func (p *parser) parseFilterQuery() (FilterQuery, error) {
+	relative := p.input[p.pos] == '@'
+	p.pos++
+	segments, err := p.parseSegments()
+	if err != nil {
+		return FilterQuery{}, err
+	}
+	return FilterQuery{Relative: relative, Segments: segments}, nil
+}
+
+// parseNumber parses a number literal, which uses JSON number syntax except that `-0` is allowed.
+func (p *parser) parseNumber() (Literal, error) {
+	start := p.pos
+	if p.peekIs('-') {
+		p.pos++
+	}
+	intStart := p.pos
+	p.skipDigits()
+	if p.pos == intStart || p.input[intStart] == '0' && p.pos-intStart > 1 {
+		return Literal{}, p.errorf("invalid number %q", p.input[start:p.pos])
+	}
+	if p.peekIs('.') {
+		p.pos++
+		if !p.skipDigits() {
+			return Literal{}, p.errorf("invalid number %q", p.input[start:p.pos])
+		}
+	}
+	if p.peekIs('e') || p.peekIs('E') {
+		p.pos++
+		if p.peekIs('-') || p.peekIs('+') {
+			p.pos++
+		}
+		if !p.skipDigits() {
+			return Literal{}, p.errorf("invalid number %q", p.input[start:p.pos])
+		}
+	}
+
+	text := danger.BytesToString(p.input[start:p.pos])
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return Literal{Value: i}, nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return Literal{Value: f}, nil
+	}
+	return Literal{}, p.errorf("invalid number %q", text)
+}
+
+// skipDigits skips a run of digits, reporting whether there were any.
+func (p *parser) skipDigits() bool {
+	start := p.pos
+	for p.pos < len(p.input) && isNumber(p.input[p.pos]) {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+// comparisonOperator consumes and returns the comparison operator after any whitespace, if there is one.
+func (p *parser) comparisonOperator() string {
+	for _, op := range [...]string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consumeOperator(op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// consumeOperator skips whitespace and then op, reporting whether op was there. Nothing is consumed when
+// it isn't.
+func (p *parser) consumeOperator(op string) bool {
+	start := p.pos
+	p.skipWhitespace()
+	if len(p.input)-p.pos >= len(op) && string(p.input[p.pos:p.pos+len(op)]) == op {
+		p.pos += len(op)
+		return true
+	}
+	p.pos = start
+	return false
+}
@@ -0,0 +1,168 @@
+// Package jsonpath parses and prints the queries dora runs, which are JSONPath queries as described in
+// RFC 9535. Parse turns a query into a typed syntax tree of segments and selectors, and String prints a
+// tree back out in canonical form, so tools can inspect queries, and build them in code and show them
+// to people.
+package jsonpath
+
+// Query is a parsed query: the root `$` followed by the segments that select from it.
+type Query struct {
+	Segments []Segment
+}
+
+// Singular reports whether the query can match at most one value, which is when every segment is a
+// child segment with a single name or index selector.
+func (q *Query) Singular() bool {
+	return singular(q.Segments)
+}
+
+// Segment is a single step of a query. A child segment applies its selectors to each value matched so
+// far, and a descendant segment (`..`) applies them to each value matched so far and every value
+// beneath it.
+type Segment struct {
+	Descendant bool
+	Selectors  []Selector // More than one selector forms a union, ex: `[0, 2]`, matched in order
+	Pos        int        // The byte offset of the segment in the query it was parsed from
+}
+
+// Selector picks children of a value. It's a NameSelector, WildcardSelector, IndexSelector,
+// SliceSelector, or FilterSelector.
+type Selector interface {
+	selector()
+}
+
+// NameSelector picks the member of an object with the given key, ex: `.name` or `['first-name']`.
+type NameSelector struct {
+	Name string // The key, with its escapes decoded
+}
+
+// WildcardSelector picks every member of an object or element of an array, `.*` or `[*]`.
+type WildcardSelector struct{}
+
+// IndexSelector picks an element of an array, ex: `[0]`, or `[-1]` for the last element.
+type IndexSelector struct {
+	Index int
+}
+
+// SliceSelector picks a range of elements of an array, ex: `[1:3]` or `[::-1]`. Bounds that are nil
+// weren't written, and default to the whole array in the direction of the step, which defaults to 1.
+type SliceSelector struct {
+	Start, End, Step *int
+}
+
+// FilterSelector picks the members or elements a filter expression is true for, ex: `[?@.age > 21]`.
+type FilterSelector struct {
+	Filter Expr
+}
+
+func (NameSelector) selector()     {}
+func (WildcardSelector) selector() {}
+func (IndexSelector) selector()    {}
+func (SliceSelector) selector()    {}
+func (FilterSelector) selector()   {}
+
+// Expr is a node of a filter expression. It's an OrExpr, AndExpr, NotExpr, ComparisonExpr,
+// FilterQuery, FunctionCall, or Literal. Filter queries and function calls returning a logical value or
+// nodes can be used as tests on their own, ex: `[?@.email]`.
+type Expr interface {
+	expr()
+}
+
+// OrExpr is true when any of its operands is true, ex: `@.a || @.b`.
+type OrExpr struct {
+	Operands []Expr
+}
+
+// AndExpr is true when all of its operands are true, ex: `@.a && @.b`.
+type AndExpr struct {
+	Operands []Expr
+}
+
+// NotExpr negates the expression it holds, ex: `!@.a`.
+type NotExpr struct {
+	Expr Expr
+}
+
+// ComparisonExpr compares two values, ex: `@.age > 21`. The operands are literals, singular queries,
+// or function calls returning a value.
+type ComparisonExpr struct {
+	Left  Expr
+	Op    string // One of `==`, `!=`, `<`, `<=`, `>`, or `>=`
+	Right Expr
+}
+
+// FilterQuery is a query inside a filter, relative to the current value (`@`) or the root (`$`).
+type FilterQuery struct {
+	Relative bool
+	Segments []Segment
+}
+
+// Singular reports whether the query can match at most one value.
+func (q FilterQuery) Singular() bool {
+	return singular(q.Segments)
+}
+
+// FunctionCall is a call to one of the function extensions, ex: `length(@.name)`.
+type FunctionCall struct {
+	Name string
+	Args []Expr
+}
+
+// Literal is a literal value in a filter. Value is a string, an int64 or float64 for numbers (int64
+// when the number is an integer that fits), a bool, or nil for null.
+type Literal struct {
+	Value any
+}
+
+func (OrExpr) expr()         {}
+func (AndExpr) expr()        {}
+func (NotExpr) expr()        {}
+func (ComparisonExpr) expr() {}
+func (FilterQuery) expr()    {}
+func (FunctionCall) expr()   {}
+func (Literal) expr()        {}
+
+// The types of function parameters and results, see RFC 9535 section 2.4.1
+const (
+	ValueType   FunctionType = iota // a single JSON value, or nothing
+	LogicalType                     // true or false
+	NodesType                       // the values selected by a query
+)
+
+// FunctionType is the type of a function parameter or result.
+type FunctionType int
+
+// Signature holds the types of a function's parameters and result.
+type Signature struct {
+	Params []FunctionType
+	Result FunctionType
+}
+
+// functions are the signatures of the function extensions defined by RFC 9535 section 2.4.
+var functions = map[string]Signature{
+	"length": {Params: []FunctionType{ValueType}, Result: ValueType},
+	"count":  {Params: []FunctionType{NodesType}, Result: ValueType},
+	"match":  {Params: []FunctionType{ValueType, ValueType}, Result: LogicalType},
+	"search": {Params: []FunctionType{ValueType, ValueType}, Result: LogicalType},
+	"value":  {Params: []FunctionType{NodesType}, Result: ValueType},
+}
+
+// Function returns the signature of the named function extension, and whether there is one.
+func Function(name string) (Signature, bool) {
+	sig, ok := functions[name]
+	return sig, ok
+}
+
+// singular reports whether segments can match at most one value.
+func singular(segments []Segment) bool {
+	for _, seg := range segments {
+		if seg.Descendant || len(seg.Selectors) != 1 {
+			return false
+		}
+		switch seg.Selectors[0].(type) {
+		case NameSelector, IndexSelector:
+		default:
+			return false
+		}
+	}
+	return true
+}
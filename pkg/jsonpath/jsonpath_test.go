@@ -0,0 +1,245 @@
+package jsonpath
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	one, two, minusOne := 1, 2, -1
+
+	tests := [...]struct {
+		input    string
+		expected []Segment
+	}{
+		{input: "$", expected: nil},
+		{
+			input: "$.data.users[0]",
+			expected: []Segment{
+				{Selectors: []Selector{NameSelector{Name: "data"}}, Pos: 1},
+				{Selectors: []Selector{NameSelector{Name: "users"}}, Pos: 6},
+				{Selectors: []Selector{IndexSelector{Index: 0}}, Pos: 12},
+			},
+		},
+		{
+			input: `$ ..['a b', "c", *] [1:2, ::-1]`,
+			expected: []Segment{
+				{Descendant: true, Selectors: []Selector{NameSelector{Name: "a b"}, NameSelector{Name: "c"}, WildcardSelector{}}, Pos: 2},
+				{Selectors: []Selector{SliceSelector{Start: &one, End: &two}, SliceSelector{Step: &minusOne}}, Pos: 20},
+			},
+		},
+		{
+			input: "$[?@.age > 21 && !@.admin || length(@.name) == 3]",
+			expected: []Segment{
+				{Selectors: []Selector{FilterSelector{Filter: OrExpr{Operands: []Expr{
+					AndExpr{Operands: []Expr{
+						ComparisonExpr{
+							Left:  FilterQuery{Relative: true, Segments: []Segment{{Selectors: []Selector{NameSelector{Name: "age"}}, Pos: 4}}},
+							Op:    ">",
+							Right: Literal{Value: int64(21)},
+						},
+						NotExpr{Expr: FilterQuery{Relative: true, Segments: []Segment{{Selectors: []Selector{NameSelector{Name: "admin"}}, Pos: 19}}}},
+					}},
+					ComparisonExpr{
+						Left: FunctionCall{Name: "length", Args: []Expr{
+							FilterQuery{Relative: true, Segments: []Segment{{Selectors: []Selector{NameSelector{Name: "name"}}, Pos: 37}}},
+						}},
+						Op:    "==",
+						Right: Literal{Value: int64(3)},
+					},
+				}}}}, Pos: 1},
+			},
+		},
+		{
+			input: "$[?($.a == 'x' || $.b != null) && match(@, 'a.*')]",
+			expected: []Segment{
+				{Selectors: []Selector{FilterSelector{Filter: AndExpr{Operands: []Expr{
+					OrExpr{Operands: []Expr{
+						ComparisonExpr{
+							Left:  FilterQuery{Segments: []Segment{{Selectors: []Selector{NameSelector{Name: "a"}}, Pos: 5}}},
+							Op:    "==",
+							Right: Literal{Value: "x"},
+						},
+						ComparisonExpr{
+							Left:  FilterQuery{Segments: []Segment{{Selectors: []Selector{NameSelector{Name: "b"}}, Pos: 19}}},
+							Op:    "!=",
+							Right: Literal{},
+						},
+					}},
+					FunctionCall{Name: "match", Args: []Expr{FilterQuery{Relative: true}, Literal{Value: "a.*"}}},
+				}}}}, Pos: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		q, err := Parse(tt.input)
+		if err != nil {
+			t.Fatalf("Failed to parse %s. Error: %v", tt.input, err)
+		}
+		if !reflect.DeepEqual(q.Segments, tt.expected) {
+			t.Fatalf("Expected %s to parse into %+v, got: %+v", tt.input, tt.expected, q.Segments)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := [...]struct {
+		input string
+		pos   int
+	}{
+		{input: "", pos: 0},
+		{input: "data", pos: 0},
+		{input: "$.", pos: 2},
+		{input: "$.data.1a", pos: 7},
+		{input: "$[01]", pos: 4},
+		{input: "$['a]", pos: 5},
+		{input: "$.a ", pos: 3},
+		{input: "$[0 1]", pos: 4},
+		{input: "$[?@.* == 1]", pos: 9},
+		{input: "$[?unknown(@.a)]", pos: 10},
+		{input: "$[?length(@.a)]", pos: 14},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("Expected a SyntaxError parsing %q, got: %v", tt.input, err)
+		}
+		if syntaxErr.Pos != tt.pos || syntaxErr.Query != tt.input {
+			t.Fatalf("Expected the error parsing %q to be at %d, got: %d (%v)", tt.input, tt.pos, syntaxErr.Pos, err)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := [...]struct {
+		input    string
+		expected string
+	}{
+		{input: "$", expected: "$"},
+		{input: `$[ "data" ]['users'][ 0 ].email`, expected: "$.data.users[0].email"},
+		{input: "$['first-name']['it\\'s']['a\\u0001\\n']", expected: `$['first-name']['it\'s']['a\u0001\n']`},
+		{input: "$..['name']..[*]..['a-b']", expected: "$..name..*..['a-b']"},
+		{input: "$[*][0,-1 , 'a',*]", expected: "$.*[0, -1, 'a', *]"},
+		{input: "$[1:3][::-1][:][:2:][1::2]", expected: "$[1:3][::-1][:][:2][1::2]"},
+		{input: "$[?(@.age>21)]", expected: "$[?@.age > 21]"},
+		{input: `$[?@.a=="x"&&(@.b||!@.c)]`, expected: "$[?@.a == 'x' && (@.b || !@.c)]"},
+		{input: "$[?!(@.a==1)]", expected: "$[?!(@.a == 1)]"},
+		{input: "$[?@.a==1.50||@.b==1e3||@.c==2.0||@.d==-0]", expected: "$[?@.a == 1.5 || @.b == 1000.0 || @.c == 2.0 || @.d == 0]"},
+		{input: "$[?@.a==true&&@.b==false&&@.c==null]", expected: "$[?@.a == true && @.b == false && @.c == null]"},
+		{input: "$[?count(@..x)>1&&search(@.s,'^a')&&value($.v)==@]", expected: "$[?count(@..x) > 1 && search(@.s, '^a') && value($.v) == @]"},
+	}
+
+	for _, tt := range tests {
+		formatted, err := Format(tt.input)
+		if err != nil {
+			t.Fatalf("Failed to format %s. Error: %v", tt.input, err)
+		}
+		if formatted != tt.expected {
+			t.Fatalf("Expected %s to format as %s, got: %s", tt.input, tt.expected, formatted)
+		}
+
+		// Canonical queries parse back into the same tree, and format the same way again
+		original, _ := Parse(tt.input)
+		reparsed, err := Parse(formatted)
+		if err != nil {
+			t.Fatalf("Failed to parse formatted query %s. Error: %v", formatted, err)
+		}
+		if !reflect.DeepEqual(withoutPositions(reparsed.Segments), withoutPositions(original.Segments)) {
+			t.Fatalf("Expected %s to parse into the same tree as %s", formatted, tt.input)
+		}
+		if again := reparsed.String(); again != formatted {
+			t.Fatalf("Expected %s to format as itself, got: %s", formatted, again)
+		}
+	}
+}
+
+func TestQuery_String(t *testing.T) {
+	// A query built in code, the way a UI might build one from the keys a user clicked through
+	q := &Query{}
+	for _, key := range []string{"data", "first name", "it's"} {
+		q.Segments = append(q.Segments, Segment{Selectors: []Selector{NameSelector{Name: key}}})
+	}
+	q.Segments = append(q.Segments, Segment{Selectors: []Selector{IndexSelector{Index: 2}}})
+	q.Segments = append(q.Segments, Segment{Selectors: []Selector{FilterSelector{Filter: NotExpr{Expr: AndExpr{Operands: []Expr{
+		FilterQuery{Relative: true, Segments: []Segment{{Selectors: []Selector{NameSelector{Name: "a"}}}}},
+		OrExpr{Operands: []Expr{
+			ComparisonExpr{Left: FilterQuery{}, Op: "<", Right: Literal{Value: 3}},
+			FunctionCall{Name: "match", Args: []Expr{FilterQuery{Relative: true}, Literal{Value: "x"}}},
+		}},
+	}}}}}})
+
+	expected := `$.data['first name']['it\'s'][2][?!(@.a && ($ < 3 || match(@, 'x')))]`
+	if s := q.String(); s != expected {
+		t.Fatalf("Expected %s, got: %s", expected, s)
+	}
+	if prefix := (&Query{Segments: q.Segments[:4]}); !prefix.Singular() {
+		t.Fatalf("Expected the query without its filter to be singular")
+	}
+	if q.Singular() {
+		t.Fatalf("Expected the query with a filter not to be singular")
+	}
+	if _, err := Parse(expected); err != nil {
+		t.Fatalf("Failed to parse the printed query. Error: %v", err)
+	}
+
+	if got := string(AppendName([]byte("$"), "ü_1")); got != "$.ü_1" {
+		t.Fatalf("Expected a dot name, got: %s", got)
+	}
+	if got := string(AppendName([]byte("$"), "1a")); got != "$['1a']" {
+		t.Fatalf("Expected a quoted name, got: %s", got)
+	}
+}
+
+// withoutPositions returns a copy of segments with their positions zeroed, so queries can be compared
+// however they were written.
+func withoutPositions(segments []Segment) []Segment {
+	var out []Segment
+	for _, seg := range segments {
+		seg.Pos = 0
+		selectors := make([]Selector, len(seg.Selectors))
+		for i, sel := range seg.Selectors {
+			if f, ok := sel.(FilterSelector); ok {
+				sel = FilterSelector{Filter: exprWithoutPositions(f.Filter)}
+			}
+			selectors[i] = sel
+		}
+		seg.Selectors = selectors
+		out = append(out, seg)
+	}
+	return out
+}
+
+func exprWithoutPositions(expr Expr) Expr {
+	switch e := expr.(type) {
+	case OrExpr:
+		operands := make([]Expr, len(e.Operands))
+		for i, operand := range e.Operands {
+			operands[i] = exprWithoutPositions(operand)
+		}
+		return OrExpr{Operands: operands}
+	case AndExpr:
+		operands := make([]Expr, len(e.Operands))
+		for i, operand := range e.Operands {
+			operands[i] = exprWithoutPositions(operand)
+		}
+		return AndExpr{Operands: operands}
+	case NotExpr:
+		return NotExpr{Expr: exprWithoutPositions(e.Expr)}
+	case ComparisonExpr:
+		return ComparisonExpr{Left: exprWithoutPositions(e.Left), Op: e.Op, Right: exprWithoutPositions(e.Right)}
+	case FilterQuery:
+		return FilterQuery{Relative: e.Relative, Segments: withoutPositions(e.Segments)}
+	case FunctionCall:
+		args := make([]Expr, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = exprWithoutPositions(arg)
+		}
+		return FunctionCall{Name: e.Name, Args: args}
+	default:
+		return expr
+	}
+}
@@ -0,0 +1,325 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/bradford-hamilton/dora/pkg/danger"
+	"github.com/bradford-hamilton/dora/pkg/token"
+)
+
+// MaxIndex is the largest index a query may use. RFC 9535 limits integers to the range that is exact in
+// an IEEE 754 double, so queries behave the same everywhere.
+const MaxIndex = 1<<53 - 1
+
+// SyntaxError is the error returned when a query can't be parsed.
+type SyntaxError struct {
+	Query string // The query that failed to parse
+	Pos   int    // The byte offset in the query where the error was found
+	Msg   string // What went wrong
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("jsonpath: %s at %d", e.Msg, e.Pos)
+}
+
+// Parse parses a query into a Query. Queries follow RFC 9535, here is a quick BNF-like representation,
+// where S is optional whitespace:
+//
+//	<query>     ::= "$" (S <segment>)*
+//	<segment>   ::= "." <name> | ".*" | <brackets> | ".." <name> | "..*" | ".." <brackets>
+//	<brackets>  ::= "[" S <selector> (S "," S <selector>)* S "]"
+//	<selector>  ::= "'<string>'" | "\"<string>\"" | "*" | <int> | <slice> | "?" <filter>
+//	<slice>     ::= (<int> S)? ":" S (<int> S)? (":" (S <int>)?)?
+//
+// Names after a `.` start with a letter, `_`, or a non-ASCII character, and continue with those or digits.
+// Any other key can be quoted in brackets. Errors are a *SyntaxError holding the position they were found at.
+func Parse(query string) (*Query, error) {
+	if len(query) == 0 || query[0] != '$' {
+		return nil, &SyntaxError{Query: query, Msg: "query must start with `$`"}
+	}
+
+	p := parser{input: danger.StringToBytes(query), pos: 1}
+	segments, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos])
+	}
+
+	return &Query{Segments: segments}, nil
+}
+
+// parser is a recursive descent parser for queries and the filters inside them.
+type parser struct {
+	input []byte
+	pos   int
+}
+
+// parseSegments parses segments until it reaches something that can't start one, which is where a query
+// inside a filter ends.
+func (p *parser) parseSegments() ([]Segment, error) {
+	var segments []Segment
+	for {
+		start := p.pos
+		p.skipWhitespace()
+		if !p.peekIs('.') && !p.peekIs('[') {
+			// Whitespace after the last segment belongs to whatever follows the query
+			p.pos = start
+			return segments, nil
+		}
+		pos := p.pos
+		seg, err := p.parseSegment()
+		if err != nil {
+			return nil, err
+		}
+		seg.Pos = pos
+		segments = append(segments, seg)
+	}
+}
+
+func (p *parser) parseSegment() (Segment, error) {
+	if p.peekIs('[') {
+		selectors, err := p.parseBrackets()
+		return Segment{Selectors: selectors}, err
+	}
+
+	// Step past the `.`, and the second one of a descendant segment
+	p.pos++
+	var seg Segment
+	if p.peekIs('.') {
+		p.pos++
+		seg.Descendant = true
+		if p.peekIs('[') {
+			selectors, err := p.parseBrackets()
+			seg.Selectors = selectors
+			return seg, err
+		}
+	}
+
+	if p.peekIs('*') {
+		p.pos++
+		seg.Selectors = []Selector{WildcardSelector{}}
+		return seg, nil
+	}
+
+	name, ok := p.parseName()
+	if !ok {
+		return Segment{}, p.errorf("expected a key or `*` after `.`")
+	}
+	seg.Selectors = []Selector{NameSelector{Name: name}}
+	return seg, nil
+}
+
+// parseName consumes a key written after a `.`, ex: the `name` in `$.name`.
+func (p *parser) parseName() (string, bool) {
+	start := p.pos
+	for p.pos < len(p.input) {
+		char := p.input[p.pos]
+		if isNameFirst(char) || (p.pos > start && isNumber(char)) {
+			p.pos++
+			continue
+		}
+		break
+	}
+	if p.pos == start || !utf8.Valid(p.input[start:p.pos]) {
+		p.pos = start
+		return "", false
+	}
+	return string(p.input[start:p.pos]), true
+}
+
+// parseBrackets parses a bracketed list of selectors, from the `[` up to and including the `]`.
+func (p *parser) parseBrackets() ([]Selector, error) {
+	p.pos++
+
+	var selectors []Selector
+	for {
+		p.skipWhitespace()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, sel)
+
+		p.skipWhitespace()
+		if p.peekIs(']') {
+			p.pos++
+			return selectors, nil
+		}
+		if !p.peekIs(',') {
+			return nil, p.errorf("expected `,` or `]` in brackets")
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) parseSelector() (Selector, error) {
+	if p.pos >= len(p.input) {
+		return nil, p.errorf("unexpected end of query, expected a selector")
+	}
+
+	switch char := p.input[p.pos]; {
+	case char == '\'' || char == '"':
+		name, err := p.parseString()
+		return NameSelector{Name: name}, err
+	case char == '*':
+		p.pos++
+		return WildcardSelector{}, nil
+	case char == '?':
+		p.pos++
+		p.skipWhitespace()
+		filter, err := p.parseOr()
+		return FilterSelector{Filter: filter}, err
+	case char == '-' || char == ':' || isNumber(char):
+		return p.parseIndexOrSlice()
+	default:
+		return nil, p.errorf("unexpected %q in brackets", char)
+	}
+}
+
+// parseIndexOrSlice parses an index selector like `-1`, or a slice selector like `1:5:2`.
+func (p *parser) parseIndexOrSlice() (Selector, error) {
+	var sl SliceSelector
+
+	if !p.peekIs(':') {
+		start, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+		sl.Start = &start
+
+		// Without a `:` this is an index rather than a slice
+		pos := p.pos
+		p.skipWhitespace()
+		if !p.peekIs(':') {
+			p.pos = pos
+			return IndexSelector{Index: start}, nil
+		}
+	}
+
+	// Step past the first `:` and parse the optional end
+	p.pos++
+	p.skipWhitespace()
+	if p.peekIs('-') || p.pos < len(p.input) && isNumber(p.input[p.pos]) {
+		end, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+		sl.End = &end
+		p.skipWhitespace()
+	}
+
+	// And the optional step after a second `:`
+	if p.peekIs(':') {
+		p.pos++
+		p.skipWhitespace()
+		if p.peekIs('-') || p.pos < len(p.input) && isNumber(p.input[p.pos]) {
+			step, err := p.parseInt()
+			if err != nil {
+				return nil, err
+			}
+			sl.Step = &step
+		}
+	}
+
+	return sl, nil
+}
+
+// parseInt parses an index or slice bound. Like RFC 9535, it doesn't allow leading zeros or `-0`.
+func (p *parser) parseInt() (int, error) {
+	start := p.pos
+	if p.peekIs('-') {
+		p.pos++
+	}
+	digitsStart := p.pos
+	for p.pos < len(p.input) && isNumber(p.input[p.pos]) {
+		p.pos++
+	}
+
+	digits := p.input[digitsStart:p.pos]
+	if len(digits) == 0 || digits[0] == '0' && (len(digits) > 1 || digitsStart > start) {
+		return 0, p.errorf("invalid index %q", p.input[start:p.pos])
+	}
+	n, err := strconv.Atoi(danger.BytesToString(p.input[start:p.pos]))
+	if err != nil || n > MaxIndex || n < -MaxIndex {
+		return 0, p.errorf("index %s is out of range", p.input[start:p.pos])
+	}
+	return n, nil
+}
+
+// parseString parses a string quoted with `'` or `"`, and returns its decoded value. Strings use the
+// same escapes as JSON strings, except that `\'` is used in place of `\"` inside single quotes.
+func (p *parser) parseString() (string, error) {
+	quote := p.input[p.pos]
+	p.pos++
+	start := p.pos
+
+	for p.pos < len(p.input) && p.input[p.pos] != quote {
+		char := p.input[p.pos]
+		if char < 0x20 {
+			return "", p.errorf("unescaped control character in string")
+		}
+		if char == '\\' {
+			p.pos++
+			if p.pos >= len(p.input) {
+				break
+			}
+			switch p.input[p.pos] {
+			case 'b', 'f', 'n', 'r', 't', '/', '\\', 'u', quote:
+			default:
+				return "", p.errorf("invalid escape `\\%c` in string", p.input[p.pos])
+			}
+		}
+		p.pos++
+	}
+	if p.pos >= len(p.input) {
+		return "", p.errorf("unterminated string")
+	}
+
+	raw := string(p.input[start:p.pos])
+	p.pos++
+
+	decoded, err := token.Unescape(raw)
+	if err != nil || !utf8.ValidString(decoded) {
+		return "", p.errorf("invalid string %q", raw)
+	}
+	return decoded, nil
+}
+
+func (p *parser) peekIs(char byte) bool {
+	return p.pos < len(p.input) && p.input[p.pos] == char
+}
+
+// skipWhitespace skips the blank space RFC 9535 allows between the parts of a query.
+func (p *parser) skipWhitespace() {
+	for p.pos < len(p.input) {
+		switch p.input[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// errorf returns a syntax error at the parser's current position.
+func (p *parser) errorf(format string, args ...any) error {
+	return &SyntaxError{Query: string(p.input), Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// isNameFirst reports whether char can start a key written after a `.`. Bytes of non-ASCII characters
+// are allowed, and checked to be valid UTF-8 once the key is consumed.
+func isNameFirst(char byte) bool {
+	return isLetter(char) || char >= utf8.RuneSelf
+}
+
+func isLetter(char byte) bool {
+	return 'a' <= char && char <= 'z' || 'A' <= char && char <= 'Z' || char == '_'
+}
+
+func isNumber(char byte) bool {
+	return '0' <= char && char <= '9'
+}
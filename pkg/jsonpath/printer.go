@@ -0,0 +1,252 @@
+package jsonpath
+
+import (
+	"bytes"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Format parses a query and prints it back out in canonical form, ex: `$[ "data" ]..[?(@.age>21)]`
+// becomes `$.data..[?@.age > 21]`.
+func Format(query string) (string, error) {
+	q, err := Parse(query)
+	if err != nil {
+		return "", err
+	}
+	return q.String(), nil
+}
+
+// String prints the query in canonical form. Whitespace is dropped, keys use dot notation when they
+// can and are otherwise quoted with `'`, brackets holding more than one selector separate them with
+// `, `, and filters are written without the optional parentheses around them, with a space on each
+// side of their operators. Parsing the output gives back the same Query.
+func (q *Query) String() string {
+	return string(appendSegments([]byte{'$'}, q.Segments))
+}
+
+// AppendName appends a child segment selecting the key name to a query, using dot notation when the
+// key can be written after a `.`, ex: `.email`, and brackets when it can't, ex: `['first-name']`.
+func AppendName(query []byte, name string) []byte {
+	if isShorthandName(name) {
+		query = append(query, '.')
+		return append(query, name...)
+	}
+	query = append(query, '[')
+	query = AppendQuoted(query, name)
+	return append(query, ']')
+}
+
+// AppendQuoted appends s to a query as a string quoted with `'`, escaped the way RFC 9535 escapes
+// normalized paths.
+func AppendQuoted(query []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	query = append(query, '\'')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\'', '\\':
+			query = append(query, '\\', c)
+		case '\b':
+			query = append(query, '\\', 'b')
+		case '\f':
+			query = append(query, '\\', 'f')
+		case '\n':
+			query = append(query, '\\', 'n')
+		case '\r':
+			query = append(query, '\\', 'r')
+		case '\t':
+			query = append(query, '\\', 't')
+		default:
+			if c < 0x20 {
+				query = append(query, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			} else {
+				query = append(query, c)
+			}
+		}
+	}
+	return append(query, '\'')
+}
+
+// isShorthandName reports whether name can be written after a `.`.
+func isShorthandName(name string) bool {
+	if name == "" || isNumber(name[0]) || !utf8.ValidString(name) {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isNameFirst(name[i]) && !isNumber(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func appendSegments(b []byte, segments []Segment) []byte {
+	for _, seg := range segments {
+		b = appendSegment(b, seg)
+	}
+	return b
+}
+
+func appendSegment(b []byte, seg Segment) []byte {
+	if seg.Descendant {
+		b = append(b, '.', '.')
+	}
+	if len(seg.Selectors) == 1 {
+		// A single key or wildcard is written after a `.`, which a descendant segment already has
+		switch sel := seg.Selectors[0].(type) {
+		case NameSelector:
+			if isShorthandName(sel.Name) {
+				if !seg.Descendant {
+					b = append(b, '.')
+				}
+				return append(b, sel.Name...)
+			}
+		case WildcardSelector:
+			if !seg.Descendant {
+				b = append(b, '.')
+			}
+			return append(b, '*')
+		}
+	}
+
+	b = append(b, '[')
+	for i, sel := range seg.Selectors {
+		if i > 0 {
+			b = append(b, ',', ' ')
+		}
+		b = appendSelector(b, sel)
+	}
+	return append(b, ']')
+}
+
+func appendSelector(b []byte, sel Selector) []byte {
+	switch s := sel.(type) {
+	case NameSelector:
+		return AppendQuoted(b, s.Name)
+	case WildcardSelector:
+		return append(b, '*')
+	case IndexSelector:
+		return strconv.AppendInt(b, int64(s.Index), 10)
+	case SliceSelector:
+		if s.Start != nil {
+			b = strconv.AppendInt(b, int64(*s.Start), 10)
+		}
+		b = append(b, ':')
+		if s.End != nil {
+			b = strconv.AppendInt(b, int64(*s.End), 10)
+		}
+		if s.Step != nil {
+			b = append(b, ':')
+			b = strconv.AppendInt(b, int64(*s.Step), 10)
+		}
+		return b
+	case FilterSelector:
+		b = append(b, '?')
+		return appendExpr(b, s.Filter)
+	default:
+		return b
+	}
+}
+
+func appendExpr(b []byte, expr Expr) []byte {
+	switch e := expr.(type) {
+	case OrExpr:
+		for i, operand := range e.Operands {
+			if i > 0 {
+				b = append(b, " || "...)
+			}
+			b = appendOperand(b, operand, isOr)
+		}
+		return b
+	case AndExpr:
+		for i, operand := range e.Operands {
+			if i > 0 {
+				b = append(b, " && "...)
+			}
+			b = appendOperand(b, operand, isOrAnd)
+		}
+		return b
+	case NotExpr:
+		b = append(b, '!')
+		return appendOperand(b, e.Expr, isLogical)
+	case ComparisonExpr:
+		b = appendOperand(b, e.Left, isLogical)
+		b = append(b, ' ')
+		b = append(b, e.Op...)
+		b = append(b, ' ')
+		return appendOperand(b, e.Right, isLogical)
+	case FilterQuery:
+		if e.Relative {
+			b = append(b, '@')
+		} else {
+			b = append(b, '$')
+		}
+		return appendSegments(b, e.Segments)
+	case FunctionCall:
+		b = append(b, e.Name...)
+		b = append(b, '(')
+		for i, arg := range e.Args {
+			if i > 0 {
+				b = append(b, ',', ' ')
+			}
+			b = appendExpr(b, arg)
+		}
+		return append(b, ')')
+	case Literal:
+		return appendLiteral(b, e.Value)
+	default:
+		return b
+	}
+}
+
+// appendOperand appends an operand of an operator, in parentheses when grouped reports it must be
+// grouped to keep its meaning.
+func appendOperand(b []byte, expr Expr, grouped func(Expr) bool) []byte {
+	if !grouped(expr) {
+		return appendExpr(b, expr)
+	}
+	b = append(b, '(')
+	b = appendExpr(b, expr)
+	return append(b, ')')
+}
+
+func isOr(expr Expr) bool {
+	_, ok := expr.(OrExpr)
+	return ok
+}
+
+func isOrAnd(expr Expr) bool {
+	_, ok := expr.(AndExpr)
+	return ok || isOr(expr)
+}
+
+func isLogical(expr Expr) bool {
+	switch expr.(type) {
+	case OrExpr, AndExpr, NotExpr, ComparisonExpr:
+		return true
+	default:
+		return false
+	}
+}
+
+func appendLiteral(b []byte, value any) []byte {
+	switch v := value.(type) {
+	case string:
+		return AppendQuoted(b, v)
+	case int64:
+		return strconv.AppendInt(b, v, 10)
+	case int:
+		return strconv.AppendInt(b, int64(v), 10)
+	case float64:
+		start := len(b)
+		b = strconv.AppendFloat(b, v, 'g', -1, 64)
+		// Whole numbers keep a fraction so they're parsed back as a float64
+		if !bytes.ContainsAny(b[start:], ".e") {
+			b = append(b, '.', '0')
+		}
+		return b
+	case bool:
+		return strconv.AppendBool(b, v)
+	default:
+		return append(b, "null"...)
+	}
+}
@@ -23,6 +23,10 @@ func New(input string) *Lexer {
 }
 
 func (l *Lexer) advanceChar() {
+	if l.readPosition > len(l.Input) {
+		// Already at the end of input, which stays at len(l.Input) so positions never run past it
+		return
+	}
 	if l.readPosition >= len(l.Input) {
 		// End of input (haven't read anything yet or EOF)
 		// 0 is ASCII code for "NUL" character
@@ -67,16 +71,21 @@ func (l *Lexer) NextToken() token.Token {
 	case '"', '\'':
 		delimiter := l.char
 		t.Type = token.String
+		t.Start = l.position
 		t.Literal = l.readString(delimiter)
 		t.Line = l.line
-		t.Start = l.position
-		t.End = l.position + 1
+		t.End = l.position
 		t.Prefix = string(delimiter)
-		t.Suffix = string(delimiter)
+		if l.char == delimiter {
+			t.End++ // include the closing delimiter
+			t.Suffix = string(delimiter)
+		}
 	case 0:
 		t.Literal = ""
 		t.Type = token.EOF
 		t.Line = l.line
+		t.Start = l.position
+		t.End = l.position
 	default:
 		if isLetter(l.char) {
 			t.Start = l.position
@@ -238,12 +247,12 @@ func (l *Lexer) readBlockComment() token.Token {
 }
 
 // readNumber sets a start position and reads through characters. When it
-// finds a char that isn't a number, it stops consuming characters and
+// finds a char that isn't part of a number, it stops consuming characters and
 // returns the string between the start and end positions.
 func (l *Lexer) readNumber() string {
 	position := l.position
 
-	for isNumber(l.char) {
+	for isNumber(l.char) || isExponent(l.char) {
 		l.advanceChar()
 	}
 
@@ -254,6 +263,12 @@ func isNumber(char byte) bool {
 	return '0' <= char && char <= '9' || char == '.' || char == '-'
 }
 
+// isExponent reports whether char can appear in the exponent part of a number, ex: `1.5e+10`.
+// These can't start a number, so they're kept separate from isNumber.
+func isExponent(char byte) bool {
+	return char == 'e' || char == 'E' || char == '+'
+}
+
 func isLetter(char byte) bool {
 	return 'a' <= char && char <= 'z'
 }
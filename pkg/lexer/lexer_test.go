@@ -115,6 +115,49 @@ func TestNextToken_WithSingleQuoteString(t *testing.T) {
 	assertLexerMatches(t, l, tests)
 }
 
+func TestNextToken_WithUnterminatedString(t *testing.T) {
+	input := `["abcdef`
+
+	tests := []token.Token{
+		{Type: token.LeftBracket, Literal: "[", Line: 0},
+		{Type: token.String, Literal: "abcdef", Line: 0, Prefix: `"`},
+		{Type: token.EOF, Literal: "", Line: 0},
+	}
+
+	l := New(input)
+
+	assertLexerMatches(t, l, tests)
+
+	// Positions stay within the input however many times the lexer is asked for a token at its end
+	for i := 0; i < 4; i++ {
+		tok := l.NextToken()
+		if tok.Start > len(input) || tok.End > len(input) {
+			t.Fatalf("Expected token positions within the input of length %d, got: %d-%d", len(input), tok.Start, tok.End)
+		}
+	}
+}
+
+func TestNextToken_WithExponentNumbers(t *testing.T) {
+	input := `[1e30, -2.5E-3, 4E+2]`
+
+	tests := []token.Token{
+		{Type: token.LeftBracket, Literal: "[", Line: 0},
+		{Type: token.Number, Literal: "1e30", Line: 0},
+		{Type: token.Comma, Literal: ",", Line: 0},
+		{Type: token.Whitespace, Literal: " ", Line: 0},
+		{Type: token.Number, Literal: "-2.5E-3", Line: 0},
+		{Type: token.Comma, Literal: ",", Line: 0},
+		{Type: token.Whitespace, Literal: " ", Line: 0},
+		{Type: token.Number, Literal: "4E+2", Line: 0},
+		{Type: token.RightBracket, Literal: "]", Line: 0},
+		{Type: token.EOF, Literal: "", Line: 0},
+	}
+
+	l := New(input)
+
+	assertLexerMatches(t, l, tests)
+}
+
 func TestNextToken(t *testing.T) {
 	input := `{
 	"items": {
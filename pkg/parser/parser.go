@@ -4,6 +4,7 @@ package parser
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -19,12 +20,18 @@ type Parser struct {
 	errors       []string
 	currentToken token.Token
 	peekToken    token.Token
+	lineStarts   []int // byte offsets where each line of the input begins
 }
 
 // New takes a Lexer, creates a Parser with that Lexer, sets the current and
 // peek tokens, and returns the Parser.
 func New(l *lexer.Lexer) *Parser {
-	p := Parser{lexer: l}
+	p := Parser{lexer: l, lineStarts: []int{0}}
+	for i, char := range l.Input {
+		if char == '\n' {
+			p.lineStarts = append(p.lineStarts, i+1)
+		}
+	}
 
 	// Read two tokens, so currentToken and peekToken are both set.
 	p.nextToken()
@@ -34,7 +41,9 @@ func New(l *lexer.Lexer) *Parser {
 }
 
 // ParseJSON parses tokens and creates an AST. It returns the RootNode
-// which holds a slice of Values (and in turn, the rest of the tree)
+// which holds a slice of Values (and in turn, the rest of the tree). When
+// the input is malformed, the error lists everything the parser couldn't
+// make sense of, and the RootNode holds whatever it could parse
 func (p *Parser) ParseJSON() (ast.RootNode, error) {
 	var rootNode ast.RootNode
 	if p.currentTokenTypeIs(token.LeftBracket) {
@@ -49,8 +58,14 @@ func (p *Parser) ParseJSON() (ast.RootNode, error) {
 		))
 		return ast.RootNode{}, errors.New(p.Errors())
 	}
+	if _, ok := val.Content.(ast.Literal); ok {
+		rootNode.Type = ast.LiteralRoot
+	}
 	rootNode.RootValue = &val
 
+	if len(p.errors) > 0 {
+		return rootNode, errors.New(p.Errors())
+	}
 	return rootNode, nil
 }
 
@@ -81,6 +96,8 @@ func (p *Parser) parseValue() ast.Value {
 		value.Content = p.parseJSONLiteral()
 	}
 
+	span := ast.SpanOf(value.Content)
+	value.Start, value.End = span.Start, span.End
 	value.SuffixStructure = p.parseStructure()
 
 	return value
@@ -101,6 +118,8 @@ func (p *Parser) parseArrayItem() ast.ArrayItem {
 		arrayItem.Value = p.parseJSONLiteral()
 	}
 
+	span := ast.SpanOf(arrayItem.Value)
+	arrayItem.Start, arrayItem.End = span.Start, span.End
 	arrayItem.PostValueStructure = p.parseStructure()
 
 	return arrayItem
@@ -117,7 +136,7 @@ func (p *Parser) parseJSONObject() ast.ValueContent {
 		case ast.ObjStart:
 			if p.currentTokenTypeIs(token.LeftBrace) {
 				objState = ast.ObjOpen
-				obj.Start = p.currentToken.Start
+				obj.Start, obj.Span.Start = p.currentToken.Start, p.position(p.currentToken.Start)
 				p.nextToken()
 			} else {
 				p.parseError(fmt.Sprintf(
@@ -127,18 +146,21 @@ func (p *Parser) parseJSONObject() ast.ValueContent {
 				return nil
 			}
 		case ast.ObjOpen:
+			structure := p.parseStructure()
 			if p.currentTokenTypeIs(token.RightBrace) {
+				obj.SuffixStructure = structure
+				obj.End, obj.Span.End = p.currentToken.End, p.position(p.currentToken.End)
 				p.nextToken()
-				obj.End = p.currentToken.End
 				return obj
 			}
 			prop := p.parseProperty()
+			prop.PrefixStructure = append(structure, prop.PrefixStructure...)
 			obj.Children = append(obj.Children, prop)
 			objState = ast.ObjProperty
 		case ast.ObjProperty:
 			if p.currentTokenTypeIs(token.RightBrace) {
+				obj.End, obj.Span.End = p.currentToken.End, p.position(p.currentToken.End)
 				p.nextToken()
-				obj.End = p.currentToken.Start
 				return obj
 			} else if p.currentTokenTypeIs(token.Comma) {
 				obj.Children[len(obj.Children)-1].HasCommaSeparator = true
@@ -155,20 +177,22 @@ func (p *Parser) parseJSONObject() ast.ValueContent {
 			structure := p.parseStructure()
 			if p.currentTokenTypeIs(token.RightBrace) {
 				obj.SuffixStructure = structure
+				obj.End, obj.Span.End = p.currentToken.End, p.position(p.currentToken.End)
 				p.nextToken()
-				obj.End = p.currentToken.End
 				return obj
 			}
 			prop := p.parseProperty()
 			prop.PrefixStructure = append(structure, prop.PrefixStructure...)
-			if prop.Value != nil {
-				obj.Children = append(obj.Children, prop)
-				objState = ast.ObjProperty
+			if prop.Value == nil {
+				// parseProperty has reported the error, and nothing after it can be parsed
+				return nil
 			}
+			obj.Children = append(obj.Children, prop)
+			objState = ast.ObjProperty
 		}
 	}
 
-	obj.End = p.currentToken.Start
+	obj.End, obj.Span.End = p.currentToken.Start, p.position(p.currentToken.Start)
 
 	return obj
 }
@@ -183,25 +207,29 @@ func (p *Parser) parseJSONArray() ast.ValueContent {
 		switch arrayState {
 		case ast.ArrayStart:
 			if p.currentTokenTypeIs(token.LeftBracket) {
-				array.Start = p.currentToken.Start
+				array.Start, array.Span.Start = p.currentToken.Start, p.position(p.currentToken.Start)
 				arrayState = ast.ArrayOpen
 				p.nextToken()
 			}
 		case ast.ArrayOpen:
+			structure := p.parseStructure()
 			if p.currentTokenTypeIs(token.RightBracket) {
-				array.End = p.currentToken.End
+				array.SuffixStructure = structure
+				array.End, array.Span.End = p.currentToken.End, p.position(p.currentToken.End)
 				p.nextToken()
 				return array
 			}
 			arrayItem := p.parseArrayItem()
+			arrayItem.PrefixStructure = append(structure, arrayItem.PrefixStructure...)
+			if arrayItem.Value == nil {
+				// parseArrayItem has reported the error, and nothing after it can be parsed
+				return nil
+			}
 			array.Children = append(array.Children, arrayItem)
 			arrayState = ast.ArrayValue
-			if p.peekTokenTypeIs(token.RightBracket) {
-				p.nextToken()
-			}
 		case ast.ArrayValue:
 			if p.currentTokenTypeIs(token.RightBracket) {
-				array.End = p.currentToken.End
+				array.End, array.Span.End = p.currentToken.End, p.position(p.currentToken.End)
 				p.nextToken()
 				return array
 			} else if p.currentTokenTypeIs(token.Comma) {
@@ -210,32 +238,40 @@ func (p *Parser) parseJSONArray() ast.ValueContent {
 				p.nextToken()
 			} else {
 				p.parseError(fmt.Sprintf(
-					"Error parsing property. Expected RightBrace or Comma token, got: %s",
+					"Error parsing array. Expected RightBracket or Comma token, got: %s",
 					p.currentToken.Literal,
 				))
+				return nil
 			}
 		case ast.ArrayComma:
 			structure := p.parseStructure()
 			if p.currentTokenTypeIs(token.RightBracket) {
 				array.SuffixStructure = structure
+				array.End, array.Span.End = p.currentToken.End, p.position(p.currentToken.End)
 				p.nextToken()
-				array.End = p.currentToken.End
 				return array
 			}
 			arrayItem := p.parseArrayItem()
-			arrayItem.PrefixStructure = append(structure, array.PrefixStructure...)
+			arrayItem.PrefixStructure = append(structure, arrayItem.PrefixStructure...)
+			if arrayItem.Value == nil {
+				return nil
+			}
 			array.Children = append(array.Children, arrayItem)
 			arrayState = ast.ArrayValue
 		}
 	}
-	array.End = p.currentToken.Start
+	array.End, array.Span.End = p.currentToken.Start, p.position(p.currentToken.Start)
 	array.SuffixStructure = p.parseStructure()
 	return array
 }
 
 // parseJSONLiteral switches on the current token's type, sets the Value on a return val and returns it.
 func (p *Parser) parseJSONLiteral() ast.Literal {
-	val := ast.Literal{Type: ast.LiteralType}
+	val := ast.Literal{
+		Type:  ast.LiteralType,
+		Start: p.position(p.currentToken.Start),
+		End:   p.position(p.currentToken.End),
+	}
 
 	// Regardless of what the current token type is - after it's been assigned, we must consume the token
 	defer p.nextToken()
@@ -258,6 +294,11 @@ func (p *Parser) parseJSONLiteral() ast.Literal {
 			return val
 		}
 		f, err := strconv.ParseFloat(ct, 64)
+		if errors.Is(err, strconv.ErrRange) {
+			// The number is valid JSON that doesn't fit in a float64, so it's kept as written
+			val.Value = ct
+			return val
+		}
 		if err != nil {
 			p.parseError("error parsing JSON number, incorrect syntax")
 			val.Value = ct
@@ -274,7 +315,12 @@ func (p *Parser) parseJSONLiteral() ast.Literal {
 		val.ValueType = ast.BooleanLiteralValueType
 		val.Value = false
 		return val
+	case token.Null:
+		val.ValueType = ast.NullLiteralValueType
+		val.Value = "null"
+		return val
 	default:
+		p.parseError(fmt.Sprintf("Error parsing JSON expected a value, got: %s", p.currentToken.Literal))
 		val.ValueType = ast.NullLiteralValueType
 		val.Value = "null"
 		return val
@@ -295,8 +341,11 @@ func (p *Parser) parseProperty() ast.Property {
 					Type:      ast.IdentifierType,
 					Value:     p.parseString(),
 					Delimiter: p.currentToken.Prefix,
+					Start:     p.position(p.currentToken.Start),
+					End:       p.position(p.currentToken.End),
 				}
 				prop.Key = key
+				prop.Start = key.Start
 				propertyState = ast.PropertyKey
 				p.nextToken()
 			} else {
@@ -304,6 +353,7 @@ func (p *Parser) parseProperty() ast.Property {
 					"Error parsing property start. Expected String token, got: %s",
 					p.currentToken.Literal,
 				))
+				return prop
 			}
 		case ast.PropertyKey:
 			prop.PostKeyStructure = p.parseStructure()
@@ -316,11 +366,13 @@ func (p *Parser) parseProperty() ast.Property {
 					"Error parsing property. Expected Colon token, got: %s",
 					p.currentToken.Literal,
 				))
+				return prop
 			}
 		case ast.PropertyColon:
 			prop.PreValueStructure = p.parseStructure()
 			val := p.parseValue()
 			prop.Value = val
+			prop.End = val.End
 			propertyState = ast.PropertyValue
 		case ast.PropertyValue:
 			prop.PostValueStructure = p.parseStructure()
@@ -345,8 +397,21 @@ func (p *Parser) parseStructure() []ast.StructuralItem {
 	}
 }
 
+// position converts a byte offset in the input into an ast.Position with line and column.
+func (p *Parser) position(offset int) ast.Position {
+	line := sort.SearchInts(p.lineStarts, offset+1) - 1
+	return ast.Position{
+		Offset: offset,
+		Line:   line + 1,
+		Column: offset - p.lineStarts[line] + 1,
+	}
+}
+
 // TODO: all the tedius ecaping, etc still needs to be applied here
 func (p *Parser) parseString() string {
+	if p.currentToken.Suffix == "" {
+		p.parseError(fmt.Sprintf("Error parsing string. Missing closing %s for: %s", p.currentToken.Prefix, p.currentToken.Literal))
+	}
 	return p.currentToken.Literal
 }
 
@@ -1,8 +1,6 @@
 package parser
 
 import (
-	"fmt"
-	"strings"
 	"testing"
 
 	"github.com/bradford-hamilton/dora/pkg/ast"
@@ -204,6 +202,52 @@ func TestParseAndWriteObjectWithArrayWithTrailingComma(t *testing.T) {
 	}
 }
 
+func TestParseAndWriteEmptyContainersWithWhitespace(t *testing.T) {
+	input := `{ "obj": { }, "arr": [ /* nothing */ ] }`
+	rewritten, err := parseAndOutputString(input)
+	if assert.NoError(t, err) {
+		assert.Equal(t, input, rewritten)
+	}
+}
+
+func TestParseAndWriteArrayWithCommentsBetweenItems(t *testing.T) {
+	input := `[ /* first */ 1, /* second */ 2, // third
+		3 ]`
+	rewritten, err := parseAndOutputString(input)
+	if assert.NoError(t, err) {
+		assert.Equal(t, input, rewritten)
+	}
+}
+
+func TestParsingMalformedProperties(t *testing.T) {
+	// Malformed properties are errors, rather than leaving the parser looping on a token it can't use
+	inputs := []string{
+		`{ 1: 2 }`, `{ "a" 1 }`, `{ "a": 1, true }`, `{ "a" }`,
+		`[1, {"a" 2}]`, `{"a":[1,2,}`, `{"a": }`, `[1 2]`,
+	}
+	for _, input := range inputs {
+		p := New(lexer.New(input))
+		if _, err := p.ParseJSON(); err == nil {
+			t.Fatalf("Expected an error parsing %s", input)
+		}
+	}
+}
+
+func TestParsingUnterminatedString(t *testing.T) {
+	for _, input := range []string{`["abcdef`, `{"a":"b`, `{"a`} {
+		p := New(lexer.New(input))
+		root, err := p.ParseJSON()
+		if err == nil && len(p.Errors()) == 0 {
+			t.Fatalf("Expected an error parsing %s", input)
+		}
+		if root.RootValue != nil {
+			if span := ast.SpanOf(root.RootValue.Content); span.End.Offset > len(input) {
+				t.Fatalf("Expected %s to end within the input, got: %v", input, span)
+			}
+		}
+	}
+}
+
 func checkParserErrors(t *testing.T, p *Parser) {
 	errors := p.Errors()
 	if len(errors) == 0 {
@@ -223,164 +267,46 @@ func parseAndOutputString(input string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-
-	var builder strings.Builder
-
-	if err := appendValue(&builder, *j.RootValue); err != nil {
-		return "", err
-	}
-
-	return builder.String(), nil
-}
-func appendValue(builder *strings.Builder, item ast.Value) error {
-	if err := appendStructure(builder, item.PrefixStructure); err != nil {
-		return err
-	}
-	if err := appendValueContent(builder, item.Content); err != nil {
-		return err
-	}
-	if err := appendStructure(builder, item.SuffixStructure); err != nil {
-		return err
-	}
-	return nil
+	return ast.Sprint(j)
 }
-func appendValueContent(builder *strings.Builder, item ast.ValueContent) error {
-	switch valueTyped := item.(type) {
-	case ast.Object:
-		return appendObject(builder, valueTyped)
-	case ast.Array:
-		return appendArray(builder, valueTyped)
-	case ast.Literal:
-		return appendLiteral(builder, valueTyped)
-	case ast.Value:
-		return appendValue(builder, valueTyped)
-	default:
-		return fmt.Errorf("Unhandled type in appendValue: %T", valueTyped)
-	}
-}
-func appendStructure(builder *strings.Builder, items []ast.StructuralItem) error {
-	for _, item := range items {
-		if _, err := builder.WriteString(item.Value); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-func appendObject(builder *strings.Builder, item ast.Object) error {
-	if _, err := builder.WriteString("{"); err != nil {
-		return err
-	}
 
-	for _, child := range item.Children {
-		if err := appendProperty(builder, child); err != nil {
-			return err
-		}
+func TestParsingNodePositions(t *testing.T) {
+	input := `{
+	"name": "dora",
+	// a comment
+	"list": [1, true]
+}`
+	l := lexer.New(input)
+	p := New(l)
+	program, err := p.ParseJSON()
+	if err != nil {
+		t.Fatalf("Failed to parse program. Error: %v", err)
 	}
 
-	if err := appendStructure(builder, item.SuffixStructure); err != nil {
-		return err
-	}
-	if _, err := builder.WriteString("}"); err != nil {
-		return err
-	}
+	obj := program.RootValue.Content.(ast.Object)
+	assert.Equal(t, 0, obj.Start)
+	assert.Equal(t, len(input), obj.End)
+	assert.Equal(t, ast.Position{Offset: 0, Line: 1, Column: 1}, obj.Span.Start)
+	assert.Equal(t, ast.Position{Offset: len(input), Line: 5, Column: 2}, obj.Span.End)
 
-	return nil
-}
-func appendProperty(builder *strings.Builder, item ast.Property) error {
-	if err := appendStructure(builder, item.PrefixStructure); err != nil {
-		return err
-	}
-	if err := appendIdentifier(builder, item.Key); err != nil {
-		return err
-	}
-	if err := appendStructure(builder, item.PostKeyStructure); err != nil {
-		return err
-	}
-	if _, err := builder.WriteString(":"); err != nil {
-		return err
-	}
-	if err := appendStructure(builder, item.PreValueStructure); err != nil {
-		return err
-	}
-	if err := appendValueContent(builder, item.Value); err != nil {
-		return err
-	}
-	if err := appendStructure(builder, item.PostValueStructure); err != nil {
-		return err
-	}
-	if item.HasCommaSeparator {
-		if _, err := builder.WriteString(","); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-func appendIdentifier(builder *strings.Builder, item ast.Identifier) error {
-	valueToWrite := fmt.Sprintf("%s%s%s", item.Delimiter, item.Value, item.Delimiter)
-	if _, err := builder.WriteString(valueToWrite); err != nil {
-		return err
-	}
-	return nil
-}
-func appendLiteral(builder *strings.Builder, item ast.Literal) error {
-	var valueToWrite string
-	if item.OriginalRendering != "" {
-		valueToWrite = item.OriginalRendering
-	} else {
-		switch item.ValueType {
-		case ast.StringLiteralValueType:
-			valueToWrite = fmt.Sprintf("%s%s%s", item.Delimiter, item.Value.(string), item.Delimiter)
-		case ast.BooleanLiteralValueType:
-			valueToWrite = fmt.Sprintf("%t", item.Value.(bool))
-		case ast.NullLiteralValueType:
-			valueToWrite = "null"
-		case ast.NumberLiteralValueType:
-			valueToWrite = fmt.Sprintf("%v", item.Value)
-		default:
-			return fmt.Errorf("Unhandled Literal Value Type: %v", item.ValueType)
-		}
-	}
+	name := obj.Children[0]
+	assert.Equal(t, ast.Position{Offset: 3, Line: 2, Column: 2}, name.Key.Start)
+	assert.Equal(t, ast.Position{Offset: 9, Line: 2, Column: 8}, name.Key.End)
+	assert.Equal(t, name.Key.Start, name.Start)
 
-	if _, err := builder.WriteString(valueToWrite); err != nil {
-		return err
-	}
-	return nil
-}
+	nameValue := name.Value.(ast.Value)
+	lit := nameValue.Content.(ast.Literal)
+	assert.Equal(t, ast.Position{Offset: 11, Line: 2, Column: 10}, lit.Start)
+	assert.Equal(t, ast.Position{Offset: 17, Line: 2, Column: 16}, lit.End)
+	assert.Equal(t, lit.End, name.End)
+	assert.Equal(t, `"dora"`, input[lit.Start.Offset:lit.End.Offset])
 
-func appendArray(builder *strings.Builder, item ast.Array) error {
-	if err := appendStructure(builder, item.PrefixStructure); err != nil {
-		return err
-	}
-	if _, err := builder.WriteString("["); err != nil {
-		return err
-	}
-	for _, arrayItem := range item.Children {
-		if err := appendArrayItem(builder, arrayItem); err != nil {
-			return err
-		}
-	}
-	if err := appendStructure(builder, item.SuffixStructure); err != nil {
-		return err
-	}
-	if _, err := builder.WriteString("]"); err != nil {
-		return err
-	}
-	return nil
-}
-func appendArrayItem(builder *strings.Builder, item ast.ArrayItem) error {
-	if err := appendStructure(builder, item.PrefixStructure); err != nil {
-		return err
-	}
-	if err := appendValueContent(builder, item.Value); err != nil {
-		return err
-	}
-	if err := appendStructure(builder, item.PostValueStructure); err != nil {
-		return err
-	}
-	if item.HasCommaSeparator {
-		if _, err := builder.WriteString(","); err != nil {
-			return err
-		}
-	}
-	return nil
+	list := obj.Children[1].Value.(ast.Value).Content.(ast.Array)
+	assert.Equal(t, "[1, true]", input[list.Start:list.End])
+	assert.Equal(t, 4, list.Span.Start.Line)
+
+	item := list.Children[1]
+	assert.Equal(t, ast.Position{Offset: 46, Line: 4, Column: 14}, item.Start)
+	assert.Equal(t, "true", input[item.Start.Offset:item.End.Offset])
+	assert.Equal(t, "4:14-4:18", ast.SpanOf(item.Value).String())
 }
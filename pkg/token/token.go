@@ -2,7 +2,12 @@
 package token
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 // All the different tokens for supporting JSON
@@ -83,13 +88,83 @@ var escapes = map[rune]int{
 	'u':  8, // 4 hexadecimal digits
 }
 
-// TODO: Come back to this for full robust JSON support
-var escapeChars = map[string]string{
-	"b": "\b", // Backspace
-	"f": "\f", // Form feed
-	"n": "\n", // New line
-	"r": "\r", // Carriage return
-	"t": "\t", // Horizontal tab
+// escapeChars maps the character following a `\` in a string to the character it represents.
+// `\u` escapes are handled separately in Unescape.
+var escapeChars = map[byte]string{
+	'"':  "\"", // Quotation mark
+	'\'': "'",  // Apostrophe, for single quoted strings
+	'\\': "\\", // Reverse solidus
+	'/':  "/",  // Solidus
+	'b':  "\b", // Backspace
+	'f':  "\f", // Form feed
+	'n':  "\n", // New line
+	'r':  "\r", // Carriage return
+	't':  "\t", // Horizontal tab
+}
+
+// Unescape decodes the escape sequences in a string literal, as it appears between its
+// delimiters in the source, and returns the string it represents. An error is returned
+// for unknown escapes, malformed `\u` escapes, and unpaired UTF-16 surrogates.
+func Unescape(literal string) (string, error) {
+	if strings.IndexByte(literal, '\\') < 0 {
+		return literal, nil
+	}
+
+	var b strings.Builder
+	b.Grow(len(literal))
+
+	for i := 0; i < len(literal); i++ {
+		if literal[i] != '\\' {
+			b.WriteByte(literal[i])
+			continue
+		}
+		if i+1 >= len(literal) {
+			return "", errors.New("invalid escape sequence at end of string")
+		}
+		i++
+		if literal[i] != 'u' {
+			char, ok := escapeChars[literal[i]]
+			if !ok {
+				return "", fmt.Errorf("invalid escape sequence: \\%c", literal[i])
+			}
+			b.WriteString(char)
+			continue
+		}
+
+		r, err := readHex4(literal[i+1:])
+		if err != nil {
+			return "", err
+		}
+		i += 4
+
+		if utf16.IsSurrogate(r) {
+			// A surrogate must be followed by its pair, ex: `\ud83d\ude00`
+			var low rune = -1
+			if i+6 < len(literal) && literal[i+1] == '\\' && literal[i+2] == 'u' {
+				low, _ = readHex4(literal[i+3:])
+			}
+			r = utf16.DecodeRune(r, low)
+			if r == utf8.RuneError {
+				return "", errors.New("invalid escape sequence: unpaired UTF-16 surrogate")
+			}
+			i += 6
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String(), nil
+}
+
+// readHex4 reads the four hexadecimal digits of a `\u` escape.
+func readHex4(s string) (rune, error) {
+	if len(s) < 4 {
+		return 0, errors.New("invalid escape sequence: \\u must be followed by 4 hexadecimal digits")
+	}
+	r, err := strconv.ParseUint(s[:4], 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid escape sequence: \\u%s", s[:4])
+	}
+	return rune(r), nil
 }
 
 // https://www.ecma-international.org/publications/files/ECMA-ST/ECMA-404.pdf